@@ -0,0 +1,374 @@
+// Package client implements a typed HTTP client of the MetaTube server,
+// see route/openapi.json for the API specification.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/metatube-community/metatube-sdk-go/errors"
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+type Client struct {
+	baseURL    *url.URL
+	token      string
+	userAgent  string
+	httpClient *http.Client
+}
+
+// New returns a client of the server at baseURL, e.g. http://localhost:8080.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url: %s", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+	}
+	// apply options.
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Providers is the name to URL maps of all providers.
+type Providers struct {
+	ActorProviders map[string]string `json:"actor_providers"`
+	MovieProviders map[string]string `json:"movie_providers"`
+}
+
+// GetProviders lists the actor and movie providers of the server.
+func (c *Client) GetProviders(ctx context.Context) (*Providers, error) {
+	providers := &Providers{}
+	if err := c.getJSON(ctx, "/v1/providers", nil, providers); err != nil {
+		return nil, err
+	}
+	return providers, nil
+}
+
+// SearchActor searches actors by keyword or URL, all providers are
+// searched if provider is empty.
+func (c *Client) SearchActor(ctx context.Context, q, provider string, fallback bool) ([]*model.ActorSearchResult, error) {
+	var results []*model.ActorSearchResult
+	if err := c.getJSON(ctx, "/v1/actors/search", searchQuery(q, provider, fallback), &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// SearchMovie searches movies by keyword or URL, all providers are
+// searched if provider is empty.
+func (c *Client) SearchMovie(ctx context.Context, q, provider string, fallback bool) ([]*model.MovieSearchResult, error) {
+	var results []*model.MovieSearchResult
+	if err := c.getJSON(ctx, "/v1/movies/search", searchQuery(q, provider, fallback), &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetActorInfo gets the actor info, it's got from database first if lazy.
+func (c *Client) GetActorInfo(ctx context.Context, provider, id string, lazy bool) (*model.ActorInfo, error) {
+	info := &model.ActorInfo{}
+	if err := c.getJSON(ctx, infoPath("actors", provider, id), lazyQuery(lazy), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// GetMovieInfo gets the movie info, it's got from database first if lazy.
+func (c *Client) GetMovieInfo(ctx context.Context, provider, id string, lazy bool) (*model.MovieInfo, error) {
+	info := &model.MovieInfo{}
+	if err := c.getJSON(ctx, infoPath("movies", provider, id), lazyQuery(lazy), info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// GetMovieReviews gets the movie reviews, it's got from database first if lazy.
+func (c *Client) GetMovieReviews(ctx context.Context, provider, id string, lazy bool) ([]*model.MovieReviewDetail, error) {
+	var reviews []*model.MovieReviewDetail
+	if err := c.getJSON(ctx, infoPath("reviews", provider, id), lazyQuery(lazy), &reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// GetMovieNFO gets the Kodi NFO XML document of the movie.
+func (c *Client) GetMovieNFO(ctx context.Context, provider, id string, lazy bool) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, infoPath("movies", provider, id)+"/nfo", lazyQuery(lazy), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+type ImageType string
+
+const (
+	PrimaryImage  ImageType = "primary"
+	ThumbImage    ImageType = "thumb"
+	BackdropImage ImageType = "backdrop"
+	PreviewImage  ImageType = "preview"
+	FanartImage   ImageType = "fanart"
+)
+
+// ImageOptions are the optional image processing params,
+// zero values are left to the server defaults.
+type ImageOptions struct {
+	// Index of the preview image, PreviewImage only.
+	Index int
+	// URL of the image to process instead of the default one.
+	URL      string
+	Ratio    *float64
+	Position *float64
+	Auto     bool
+	Badge    string
+	Quality  int
+	// Format of the output image, negotiated by Accept if empty.
+	Format string
+	Width  int
+	Height int
+	Fit    string
+	DPR    float64
+}
+
+// Values returns the query params of the image routes.
+func (opts *ImageOptions) Values() url.Values {
+	query := url.Values{}
+	if opts == nil {
+		return query
+	}
+	setString := func(key, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	setInt := func(key string, value int) {
+		if value != 0 {
+			query.Set(key, strconv.Itoa(value))
+		}
+	}
+	setFloat := func(key string, value float64) {
+		query.Set(key, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	setString("url", opts.URL)
+	if opts.Ratio != nil {
+		setFloat("ratio", *opts.Ratio)
+	}
+	if opts.Position != nil {
+		setFloat("pos", *opts.Position)
+	}
+	if opts.Auto {
+		query.Set("auto", "true")
+	}
+	setString("badge", opts.Badge)
+	setInt("quality", opts.Quality)
+	setString("format", opts.Format)
+	setInt("w", opts.Width)
+	setInt("h", opts.Height)
+	setString("fit", opts.Fit)
+	if opts.DPR != 0 {
+		setFloat("dpr", opts.DPR)
+	}
+	return query
+}
+
+// Image is an encoded image.
+type Image struct {
+	ContentType string
+	Data        []byte
+}
+
+// ImageURL returns the URL of the image route, note that it's not
+// signed, use the image URLs in info responses if signing is enabled.
+func (c *Client) ImageURL(typ ImageType, provider, id string, opts *ImageOptions) string {
+	u := c.url(imagePath(typ, provider, id, opts), opts.Values())
+	return u.String()
+}
+
+// GetImage gets the processed image.
+func (c *Client) GetImage(ctx context.Context, typ ImageType, provider, id string, opts *ImageOptions) (*Image, error) {
+	resp, err := c.do(ctx, http.MethodGet, imagePath(typ, provider, id, opts), opts.Values(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		ContentType: resp.Header.Get("Content-Type"),
+		Data:        data,
+	}, nil
+}
+
+type TranslateResult struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Detected string `json:"detected,omitempty"`
+	Text     string `json:"translated_text"`
+}
+
+type TranslateBatchResult struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Detected []string `json:"detected,omitempty"`
+	Texts    []string `json:"translated_texts"`
+}
+
+// Translate translates q with the engine, the source language is
+// detected if from is empty or auto. The engine configs (e.g. api-key)
+// are passed by config.
+func (c *Client) Translate(ctx context.Context, q, from, to, engine string, config url.Values) (*TranslateResult, error) {
+	query := url.Values{}
+	for k, v := range config {
+		query[k] = v
+	}
+	query.Set("q", q)
+	query.Set("to", to)
+	query.Set("engine", engine)
+	if from != "" {
+		query.Set("from", from)
+	}
+	result := &TranslateResult{}
+	if err := c.getJSON(ctx, "/v1/translate", query, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TranslateBatch translates texts in a single request.
+func (c *Client) TranslateBatch(ctx context.Context, texts []string, from, to, engine string, config url.Values) (*TranslateBatchResult, error) {
+	body, err := json.Marshal(map[string]any{
+		"q":      texts,
+		"from":   from,
+		"to":     to,
+		"engine": engine,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/v1/translate/batch", config, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	result := &TranslateBatchResult{}
+	if err = decodeData(resp.Body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// url returns the absolute url of the escaped path.
+func (c *Client) url(path string, query url.Values) *url.URL {
+	u := *c.baseURL
+	u.RawPath = u.EscapedPath() + path
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+	return &u
+}
+
+// do sends the request, and converts the error response to *errors.HTTPError.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path, query).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		msg := &responseMessage{}
+		if json.NewDecoder(resp.Body).Decode(msg) == nil && msg.Error != nil {
+			return nil, msg.Error
+		}
+		return nil, errors.FromCode(resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v any) error {
+	resp, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeData(resp.Body, v)
+}
+
+type responseMessage struct {
+	Data  json.RawMessage   `json:"data,omitempty"`
+	Error *errors.HTTPError `json:"error,omitempty"`
+}
+
+func decodeData(r io.Reader, v any) error {
+	msg := &responseMessage{}
+	if err := json.NewDecoder(r).Decode(msg); err != nil {
+		return err
+	}
+	if msg.Error != nil {
+		return msg.Error
+	}
+	return json.Unmarshal(msg.Data, v)
+}
+
+func searchQuery(q, provider string, fallback bool) url.Values {
+	query := url.Values{
+		"q":        {q},
+		"fallback": {strconv.FormatBool(fallback)},
+	}
+	if provider != "" {
+		query.Set("provider", provider)
+	}
+	return query
+}
+
+func lazyQuery(lazy bool) url.Values {
+	return url.Values{"lazy": {strconv.FormatBool(lazy)}}
+}
+
+func infoPath(typ, provider, id string) string {
+	return fmt.Sprintf("/v1/%s/%s/%s", typ, url.PathEscape(provider), url.PathEscape(id))
+}
+
+func imagePath(typ ImageType, provider, id string, opts *ImageOptions) string {
+	path := fmt.Sprintf("/v1/images/%s/%s/%s", typ, url.PathEscape(provider), url.PathEscape(id))
+	if typ == PreviewImage {
+		index := 0
+		if opts != nil {
+			index = opts.Index
+		}
+		path += "/" + strconv.Itoa(index)
+	}
+	return path
+}
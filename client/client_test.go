@@ -0,0 +1,166 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"github.com/metatube-community/metatube-sdk-go/database"
+	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/errors"
+	"github.com/metatube-community/metatube-sdk-go/model"
+	"github.com/metatube-community/metatube-sdk-go/route"
+	"github.com/metatube-community/metatube-sdk-go/route/auth"
+)
+
+const testToken = "test-token"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	img := image.NewRGBA(image.Rect(0, 0, 800, 538))
+	for x := range 800 {
+		for y := range 538 {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	buf := &bytes.Buffer{}
+	require.NoError(t, png.Encode(buf, img))
+	imageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	}))
+	t.Cleanup(imageServer.Close)
+
+	db, err := database.Open(&database.Config{
+		DSN:                  filepath.Join(t.TempDir(), "metatube.db"),
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	app := engine.New(db)
+	require.NoError(t, app.DBAutoMigrate(true))
+
+	releaseDate := datatypes.Date(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, db.Create(&model.MovieInfo{
+		ID:          "1234",
+		Number:      "HEYZO-1234",
+		Title:       "Test Movie",
+		Provider:    "HEYZO",
+		Homepage:    "https://www.heyzo.com/moviepages/1234/index.html",
+		Actors:      []string{"Actor A"},
+		ThumbURL:    imageServer.URL + "/thumb.png",
+		CoverURL:    imageServer.URL + "/cover.png",
+		Genres:      []string{"Genre A"},
+		ReleaseDate: releaseDate,
+	}).Error)
+	require.NoError(t, db.Create(&model.MovieReviewInfo{
+		ID:       "1234",
+		Provider: "HEYZO",
+		Reviews: datatypes.NewJSONType([]*model.MovieReviewDetail{
+			{Author: "Reviewer", Comment: "Good", Score: 5, Date: releaseDate},
+		}),
+	}).Error)
+
+	server := httptest.NewServer(route.New(app, auth.Token(testToken)))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient(t *testing.T) {
+	server := newTestServer(t)
+	c, err := New(server.URL, WithToken(testToken))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	providers, err := c.GetProviders(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, providers.MovieProviders, "HEYZO")
+	assert.NotEmpty(t, providers.ActorProviders)
+
+	movie, err := c.GetMovieInfo(ctx, "HEYZO", "1234", true)
+	require.NoError(t, err)
+	assert.Equal(t, "HEYZO-1234", movie.Number)
+	assert.Equal(t, []string{"Actor A"}, []string(movie.Actors))
+
+	results, err := c.SearchMovie(ctx, "1234", "HEYZO", true)
+	require.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "Test Movie", results[0].Title)
+	}
+
+	reviews, err := c.GetMovieReviews(ctx, "HEYZO", "1234", true)
+	require.NoError(t, err)
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, "Good", reviews[0].Comment)
+	}
+
+	nfo, err := c.GetMovieNFO(ctx, "HEYZO", "1234", true)
+	require.NoError(t, err)
+	assert.Contains(t, string(nfo), "<title>Test Movie</title>")
+
+	img, err := c.GetImage(ctx, ThumbImage, "HEYZO", "1234", &ImageOptions{Format: "png", Width: 100})
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", img.ContentType)
+	cfg, err := png.DecodeConfig(bytes.NewReader(img.Data))
+	require.NoError(t, err)
+	assert.Equal(t, 100, cfg.Width)
+}
+
+func TestClientError(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	c, err := New(server.URL, WithToken("invalid"))
+	require.NoError(t, err)
+	_, err = c.GetMovieInfo(ctx, "HEYZO", "1234", true)
+	var e *errors.HTTPError
+	if assert.ErrorAs(t, err, &e) {
+		assert.Equal(t, http.StatusUnauthorized, e.Code)
+	}
+
+	c, err = New(server.URL, WithToken(testToken))
+	require.NoError(t, err)
+	_, err = c.GetMovieInfo(ctx, "NotExist", "1234", true)
+	if assert.ErrorAs(t, err, &e) {
+		assert.Equal(t, http.StatusNotFound, e.Code)
+	}
+	_, err = c.GetActorInfo(ctx, "NotExist", "1234", true)
+	if assert.ErrorAs(t, err, &e) {
+		assert.Equal(t, http.StatusNotFound, e.Code)
+	}
+	_, err = c.GetImage(ctx, PrimaryImage, "NotExist", "1234", nil)
+	if assert.ErrorAs(t, err, &e) {
+		assert.Equal(t, http.StatusNotFound, e.Code)
+	}
+
+	_, err = New("localhost:8080")
+	assert.Error(t, err)
+}
+
+func TestImageURL(t *testing.T) {
+	c, err := New("http://localhost:8080/")
+	require.NoError(t, err)
+	ratio := 0.7
+	assert.Equal(t, "http://localhost:8080/v1/images/primary/HEYZO/1234?badge=%E5%AD%97%E5%B9%95&ratio=0.7",
+		c.ImageURL(PrimaryImage, "HEYZO", "1234", &ImageOptions{Ratio: &ratio, Badge: "字幕"}))
+	assert.Equal(t, "http://localhost:8080/v1/images/preview/HEYZO/1234/2",
+		c.ImageURL(PreviewImage, "HEYZO", "1234", &ImageOptions{Index: 2}))
+
+	// path segments are escaped.
+	assert.Equal(t, "http://localhost:8080/v1/images/thumb/Some%20Provider/a%2Fb%3Fc%23d",
+		c.ImageURL(ThumbImage, "Some Provider", "a/b?c#d", nil))
+
+	c, err = New("http://localhost:8080/metatube%2Fapi")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/metatube%2Fapi/v1/images/thumb/HEYZO/a%2Fb",
+		c.ImageURL(ThumbImage, "HEYZO", "a/b", nil))
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"net/http"
+)
+
+type Option func(*Client)
+
+// WithToken sets the bearer token to access the server.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient sets the HTTP client to send requests,
+// http.DefaultClient is used by default.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithUserAgent sets the User-Agent header of requests.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
@@ -4,11 +4,16 @@ import (
 	goflag "flag"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/gin-gonic/gin"
 	"github.com/peterbourgon/ff/v3"
+	"gorm.io/gorm"
 
+	"github.com/metatube-community/metatube-sdk-go/common/diskcache"
 	"github.com/metatube-community/metatube-sdk-go/database"
 	"github.com/metatube-community/metatube-sdk-go/engine"
 	"github.com/metatube-community/metatube-sdk-go/internal/envconfig"
@@ -18,6 +23,7 @@ import (
 	"github.com/metatube-community/metatube-sdk-go/provider/fc2ppvdb"
 	"github.com/metatube-community/metatube-sdk-go/route"
 	"github.com/metatube-community/metatube-sdk-go/route/auth"
+	"github.com/metatube-community/metatube-sdk-go/translate"
 )
 
 var Config = &struct {
@@ -27,8 +33,25 @@ var Config = &struct {
 	Token string
 	DSN   string
 
+	// scoped token config
+	TokenFile           string
+	TokenDB             bool
+	TokenReloadInterval time.Duration
+
+	// image signing config
+	ImageSigningKey string
+	ImageURLTTL     time.Duration
+	ImageMaxSize    int
+
+	// image cache config
+	ImageCacheDir        string
+	ImageCacheSize       int64
+	ImageSourceCacheSize int64
+	ImageCacheTTL        time.Duration
+
 	// engine config
-	RequestTimeout time.Duration
+	RequestTimeout    time.Duration
+	ImageAllowedHosts string
 
 	// database config
 	DBMaxIdleConns int
@@ -39,6 +62,10 @@ var Config = &struct {
 	// fc2 meta db config
 	FC2MetaDBPath string
 
+	// translate config
+	TranslateGlossaryPath string
+	TranslateGlossaryDB   bool
+
 	// version flag
 	VersionFlag bool
 }{}
@@ -55,12 +82,25 @@ func init() {
 	flag.StringVar(&Config.Port, "port", "8080", "Port number of server")
 	flag.StringVar(&Config.Token, "token", "", "Token to access server")
 	flag.StringVar(&Config.DSN, "dsn", "", "Database Service Name")
+	flag.StringVar(&Config.TokenFile, "token-file", "", "Path to JSON file of scoped tokens")
+	flag.BoolVar(&Config.TokenDB, "token-db", false, "Load scoped tokens from database")
+	flag.DurationVar(&Config.TokenReloadInterval, "token-reload-interval", 30*time.Second, "Interval to reload scoped tokens")
+	flag.StringVar(&Config.ImageSigningKey, "image-signing-key", "", "Secret key to sign image URLs")
+	flag.DurationVar(&Config.ImageURLTTL, "image-url-ttl", 7*24*time.Hour, "Expiry of signed image URLs")
+	flag.IntVar(&Config.ImageMaxSize, "image-max-size", 4096, "Max width/height of resized images")
+	flag.StringVar(&Config.ImageCacheDir, "image-cache-dir", "", "Directory to cache images on disk")
+	flag.Int64Var(&Config.ImageCacheSize, "image-cache-size", 1024, "Max size in MiB of processed image cache")
+	flag.Int64Var(&Config.ImageSourceCacheSize, "image-source-cache-size", 1024, "Max size in MiB of source image cache")
+	flag.DurationVar(&Config.ImageCacheTTL, "image-cache-ttl", 7*24*time.Hour, "Expiry of cached images")
 	flag.DurationVar(&Config.RequestTimeout, "request-timeout", engine.DefaultRequestTimeout, "Timeout per request")
+	flag.StringVar(&Config.ImageAllowedHosts, "image-allowed-hosts", "", "Comma-separated extra hosts allowed for image URLs, e.g. *.example.com, add the CDNs of images that providers do not declare")
 	flag.IntVar(&Config.DBMaxIdleConns, "db-max-idle-conns", 0, "Database max idle connections")
 	flag.IntVar(&Config.DBMaxOpenConns, "db-max-open-conns", 0, "Database max open connections")
 	flag.BoolVar(&Config.DBAutoMigrate, "db-auto-migrate", false, "Database auto migration")
 	flag.BoolVar(&Config.DBPreparedStmt, "db-prepared-stmt", false, "Database prepared statement")
 	flag.StringVar(&Config.FC2MetaDBPath, "fc2-meta-db-path", "fc2-meta.db", "Path to FC2 auxiliary metadata SQLite database")
+	flag.StringVar(&Config.TranslateGlossaryPath, "translate-glossary-path", "", "Path to JSON file of protected translation terms")
+	flag.BoolVar(&Config.TranslateGlossaryDB, "translate-glossary-db", false, "Protect actor names from database in translation")
 	flag.BoolVar(&Config.VersionFlag, "version", false, "Show version")
 	ff.Parse(flag, os.Args[1:], ff.WithEnvVars())
 }
@@ -85,6 +125,18 @@ func Router(names ...string) *gin.Engine {
 		opts = append(opts, engine.WithRequestTimeout(Config.RequestTimeout))
 	}
 
+	// allow extra image hosts
+	if Config.ImageAllowedHosts != "" {
+		opts = append(opts, engine.WithAllowedImageHosts(
+			strings.Split(Config.ImageAllowedHosts, ",")...))
+	}
+
+	// cache source images
+	if Config.ImageCacheDir != "" {
+		opts = append(opts, engine.WithImageSourceCache(
+			newDiskCache("source", Config.ImageSourceCacheSize)))
+	}
+
 	// specify engine name
 	for _, name := range names {
 		opts = append(opts, engine.WithEngineName(name))
@@ -121,9 +173,79 @@ func Router(names ...string) *gin.Engine {
 	}
 
 	var token auth.Validator
-	if Config.Token != "" {
+	switch {
+	case Config.TokenFile != "" || Config.TokenDB:
+		token = newScopedTokenStore(db)
+	case Config.Token != "":
 		token = auth.Token(Config.Token)
 	}
 
-	return route.New(app, token)
+	var routeOpts []route.Option
+	if glossary := loadGlossary(app); glossary != nil {
+		routeOpts = append(routeOpts, route.WithTranslateGlossary(glossary))
+	}
+	if Config.ImageMaxSize > 0 {
+		routeOpts = append(routeOpts, route.WithMaxImageSize(Config.ImageMaxSize))
+	}
+	if Config.ImageCacheDir != "" {
+		routeOpts = append(routeOpts, route.WithImageCache(
+			newDiskCache("processed", Config.ImageCacheSize)))
+	}
+	if Config.ImageSigningKey != "" {
+		routeOpts = append(routeOpts, route.WithImageSigner(
+			auth.NewURLSigner([]byte(Config.ImageSigningKey)), Config.ImageURLTTL))
+	}
+
+	return route.New(app, token, routeOpts...)
+}
+
+func newDiskCache(name string, sizeMiB int64) *diskcache.Cache {
+	cache, err := diskcache.New(filepath.Join(Config.ImageCacheDir, name),
+		sizeMiB*units.MiB, Config.ImageCacheTTL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// prune expired images in background.
+	go cache.Start(time.Hour)
+	return cache
+}
+
+func newScopedTokenStore(db *gorm.DB) *auth.ScopedTokenStore {
+	var loader auth.Loader
+	if Config.TokenFile != "" {
+		loader = auth.FileLoader(Config.TokenFile)
+	} else {
+		loader = auth.DBLoader(db)
+	}
+	store, err := auth.NewScopedTokenStore(loader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if Config.TokenReloadInterval > 0 {
+		go store.AutoReload(Config.TokenReloadInterval, nil)
+	}
+	return store
+}
+
+func loadGlossary(app *engine.Engine) *translate.Glossary {
+	if Config.TranslateGlossaryPath == "" && !Config.TranslateGlossaryDB {
+		return nil
+	}
+	glossary := translate.NewGlossary()
+	if Config.TranslateGlossaryPath != "" {
+		f, err := os.Open(Config.TranslateGlossaryPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		if err = glossary.Load(f); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if Config.TranslateGlossaryDB {
+		if err := app.LoadGlossary(glossary); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return glossary
 }
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/metatube-community/metatube-sdk-go/client"
+	"github.com/metatube-community/metatube-sdk-go/database"
+	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/engine/providerid"
+	"github.com/metatube-community/metatube-sdk-go/model"
+	"github.com/metatube-community/metatube-sdk-go/nfo"
+	"github.com/metatube-community/metatube-sdk-go/route"
+	"github.com/metatube-community/metatube-sdk-go/translate"
+)
+
+// backend is either an in-process engine or a remote server.
+type backend interface {
+	SearchActor(ctx context.Context, q, provider string, fallback bool) ([]*model.ActorSearchResult, error)
+	SearchMovie(ctx context.Context, q, provider string, fallback bool) ([]*model.MovieSearchResult, error)
+	GetActorInfo(ctx context.Context, provider, id string, lazy bool) (*model.ActorInfo, error)
+	GetMovieInfo(ctx context.Context, provider, id string, lazy bool) (*model.MovieInfo, error)
+	GetMovieReviews(ctx context.Context, provider, id string, lazy bool) ([]*model.MovieReviewDetail, error)
+	GetMovieNFO(ctx context.Context, provider, id string, lazy bool) ([]byte, error)
+	GetImage(ctx context.Context, typ client.ImageType, provider, id string, opts *client.ImageOptions) (*client.Image, error)
+	Translate(ctx context.Context, q, from, to, engine string, config url.Values) (*client.TranslateResult, error)
+}
+
+var _ backend = (*client.Client)(nil)
+
+func newBackend() (backend, error) {
+	if globalConfig.Remote != "" {
+		return client.New(globalConfig.Remote, client.WithToken(globalConfig.Token))
+	}
+	app, _, err := newEngine()
+	if err != nil {
+		return nil, err
+	}
+	return &localBackend{app: app}, nil
+}
+
+// openDB opens the database of the in-process engine.
+func openDB() (*gorm.DB, error) {
+	return database.Open(&database.Config{
+		DSN:                  globalConfig.DSN,
+		LogLevel:             logger.Silent,
+		DisableAutomaticPing: true,
+	})
+}
+
+// newEngine opens the database and returns an in-process engine.
+func newEngine() (*engine.Engine, *gorm.DB, error) {
+	db, err := openDB()
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := []engine.Option{engine.WithLogOutput(stderr)}
+	if globalConfig.Timeout > 0 {
+		opts = append(opts, engine.WithRequestTimeout(globalConfig.Timeout))
+	}
+	app := engine.New(db, opts...)
+	// always migrate the sqlite DB, same as the server.
+	if err = app.DBAutoMigrate(app.DBDriver() == database.Sqlite); err != nil {
+		return nil, nil, err
+	}
+	return app, db, nil
+}
+
+// localBackend drives an in-process engine, it behaves the same as
+// the server routes.
+type localBackend struct {
+	app *engine.Engine
+}
+
+func (b *localBackend) SearchActor(_ context.Context, q, provider string, fallback bool) ([]*model.ActorSearchResult, error) {
+	if isURL(q) {
+		info, err := b.app.GetActorInfoByURL(q, true /* always lazy */)
+		if err != nil {
+			return nil, err
+		}
+		return []*model.ActorSearchResult{info.ToSearchResult()}, nil
+	}
+	if provider == "" {
+		return b.app.SearchActorAll(q, fallback)
+	}
+	return b.app.SearchActor(q, provider, fallback)
+}
+
+func (b *localBackend) SearchMovie(_ context.Context, q, provider string, fallback bool) ([]*model.MovieSearchResult, error) {
+	if isURL(q) {
+		info, err := b.app.GetMovieInfoByURL(q, true /* always lazy */)
+		if err != nil {
+			return nil, err
+		}
+		return []*model.MovieSearchResult{info.ToSearchResult()}, nil
+	}
+	if provider == "" {
+		return b.app.SearchMovieAll(q, fallback)
+	}
+	return b.app.SearchMovie(q, provider, fallback)
+}
+
+func (b *localBackend) GetActorInfo(_ context.Context, provider, id string, lazy bool) (*model.ActorInfo, error) {
+	return b.app.GetActorInfoByProviderID(providerid.ProviderID{Provider: provider, ID: id}, lazy)
+}
+
+func (b *localBackend) GetMovieInfo(_ context.Context, provider, id string, lazy bool) (*model.MovieInfo, error) {
+	return b.app.GetMovieInfoByProviderID(providerid.ProviderID{Provider: provider, ID: id}, lazy)
+}
+
+func (b *localBackend) GetMovieReviews(_ context.Context, provider, id string, lazy bool) ([]*model.MovieReviewDetail, error) {
+	reviews, err := b.app.GetMovieReviewsByProviderID(providerid.ProviderID{Provider: provider, ID: id}, lazy)
+	if err != nil {
+		return nil, err
+	}
+	return reviews.Reviews.Data(), nil
+}
+
+func (b *localBackend) GetMovieNFO(ctx context.Context, provider, id string, lazy bool) ([]byte, error) {
+	info, err := b.GetMovieInfo(ctx, provider, id, lazy)
+	if err != nil {
+		return nil, err
+	}
+	var opts []nfo.Option
+	// actor infos are optional, only saved ones are used.
+	if actors, err := b.app.GetActorInfosByNamesFromDB(info.Actors...); err == nil {
+		opts = append(opts, nfo.WithActors(actors, func(actor *model.ActorInfo) string {
+			if len(actor.Images) > 0 {
+				return actor.Images[0]
+			}
+			return ""
+		}))
+	}
+	return nfo.Marshal(info, opts...)
+}
+
+func (b *localBackend) GetImage(_ context.Context, typ client.ImageType, provider, id string, opts *client.ImageOptions) (*client.Image, error) {
+	index := 0
+	if opts != nil {
+		index = opts.Index
+	}
+	// process the same way as the server.
+	format, data, err := route.GetImage(b.app, string(typ), provider, id, index, opts.Values())
+	if err != nil {
+		return nil, err
+	}
+	return &client.Image{
+		ContentType: format.MIMEType,
+		Data:        data,
+	}, nil
+}
+
+func (b *localBackend) Translate(_ context.Context, q, from, to, engine string, config url.Values) (*client.TranslateResult, error) {
+	if from == "" {
+		from = "auto"
+	}
+	var opts []route.Option
+	glossary, err := loadGlossary(b.app)
+	if err != nil {
+		return nil, err
+	}
+	if glossary != nil {
+		opts = append(opts, route.WithTranslateGlossary(glossary))
+	}
+	// translate the same way as the server.
+	text, detected, err := route.Translate(q, from, to, engine, config, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &client.TranslateResult{
+		From:     from,
+		To:       to,
+		Detected: detected,
+		Text:     text,
+	}, nil
+}
+
+// loadGlossary loads the protected terms of translations, it returns nil
+// if no glossary is set.
+func loadGlossary(app *engine.Engine) (*translate.Glossary, error) {
+	if globalConfig.Glossary == "" && !globalConfig.GlossaryDB {
+		return nil, nil
+	}
+	glossary := translate.NewGlossary()
+	if globalConfig.Glossary != "" {
+		f, err := os.Open(globalConfig.Glossary)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if err = glossary.Load(f); err != nil {
+			return nil, err
+		}
+	}
+	if globalConfig.GlossaryDB {
+		if err := app.LoadGlossary(glossary); err != nil {
+			return nil, err
+		}
+	}
+	return glossary, nil
+}
+
+func isURL(s string) bool {
+	_, err := url.ParseRequestURI(s)
+	return err == nil
+}
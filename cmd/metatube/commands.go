@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/metatube-community/metatube-sdk-go/client"
+	mt "github.com/metatube-community/metatube-sdk-go/provider"
+)
+
+func searchCommand() *ffcli.Command {
+	fs := flag.NewFlagSet("metatube search", flag.ExitOnError)
+	actor := fs.Bool("actor", false, "Search actors instead of movies")
+	provider := fs.String("provider", "", "Search with this provider only")
+	fallback := fs.Bool("fallback", true, "Fallback to database results")
+	return &ffcli.Command{
+		Name:       "search",
+		ShortUsage: "metatube search [flags] <keyword|url>",
+		ShortHelp:  "Search movies or actors",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return flag.ErrHelp
+			}
+			b, err := newBackend()
+			if err != nil {
+				return err
+			}
+			if *actor {
+				results, err := b.SearchActor(ctx, args[0], *provider, *fallback)
+				if err != nil {
+					return err
+				}
+				return printActorSearchResults(results)
+			}
+			results, err := b.SearchMovie(ctx, args[0], *provider, *fallback)
+			if err != nil {
+				return err
+			}
+			return printMovieSearchResults(results)
+		},
+	}
+}
+
+func infoCommand() *ffcli.Command {
+	fs := flag.NewFlagSet("metatube info", flag.ExitOnError)
+	lazy := fs.Bool("lazy", true, "Get from database first")
+	return &ffcli.Command{
+		Name:       "info",
+		ShortUsage: "metatube info [flags] <provider> <id> | <url>",
+		ShortHelp:  "Get movie info, -output nfo prints Kodi NFO",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			b, err := newBackend()
+			if err != nil {
+				return err
+			}
+			provider, id, err := resolveProviderID(args, func(u string) (string, string, error) {
+				results, err := b.SearchMovie(ctx, u, "", false)
+				if err != nil {
+					return "", "", err
+				}
+				if len(results) == 0 {
+					return "", "", mt.ErrInfoNotFound
+				}
+				return results[0].Provider, results[0].ID, nil
+			})
+			if err != nil {
+				return err
+			}
+			if globalConfig.Output == outputNFO {
+				data, err := b.GetMovieNFO(ctx, provider, id, *lazy)
+				if err != nil {
+					return err
+				}
+				_, err = stdout.Write(data)
+				return err
+			}
+			info, err := b.GetMovieInfo(ctx, provider, id, *lazy)
+			if err != nil {
+				return err
+			}
+			return printMovieInfo(info)
+		},
+	}
+}
+
+func actorCommand() *ffcli.Command {
+	fs := flag.NewFlagSet("metatube actor", flag.ExitOnError)
+	lazy := fs.Bool("lazy", true, "Get from database first")
+	return &ffcli.Command{
+		Name:       "actor",
+		ShortUsage: "metatube actor [flags] <provider> <id> | <url>",
+		ShortHelp:  "Get actor info",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			b, err := newBackend()
+			if err != nil {
+				return err
+			}
+			provider, id, err := resolveProviderID(args, func(u string) (string, string, error) {
+				results, err := b.SearchActor(ctx, u, "", false)
+				if err != nil {
+					return "", "", err
+				}
+				if len(results) == 0 {
+					return "", "", mt.ErrInfoNotFound
+				}
+				return results[0].Provider, results[0].ID, nil
+			})
+			if err != nil {
+				return err
+			}
+			info, err := b.GetActorInfo(ctx, provider, id, *lazy)
+			if err != nil {
+				return err
+			}
+			return printActorInfo(info)
+		},
+	}
+}
+
+func reviewsCommand() *ffcli.Command {
+	fs := flag.NewFlagSet("metatube reviews", flag.ExitOnError)
+	lazy := fs.Bool("lazy", true, "Get from database first")
+	return &ffcli.Command{
+		Name:       "reviews",
+		ShortUsage: "metatube reviews [flags] <provider> <id>",
+		ShortHelp:  "Get movie reviews",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) != 2 {
+				return flag.ErrHelp
+			}
+			b, err := newBackend()
+			if err != nil {
+				return err
+			}
+			reviews, err := b.GetMovieReviews(ctx, args[0], args[1], *lazy)
+			if err != nil {
+				return err
+			}
+			return printMovieReviews(reviews)
+		},
+	}
+}
+
+func imageCommand() *ffcli.Command {
+	fs := flag.NewFlagSet("metatube image", flag.ExitOnError)
+	typ := fs.String("type", string(client.PrimaryImage), "Image type: primary, thumb, backdrop, preview or fanart")
+	out := fs.String("o", "", "Output file, defaults to <provider>-<id>-<type>.<ext>")
+	opts := &client.ImageOptions{}
+	fs.IntVar(&opts.Index, "index", 0, "Index of preview image")
+	fs.StringVar(&opts.URL, "url", "", "Image URL to process instead")
+	ratio := fs.Float64("ratio", -1, "Crop ratio of primary image")
+	pos := fs.Float64("pos", -1, "Crop position")
+	fs.BoolVar(&opts.Auto, "auto", false, "Crop by face detection")
+	fs.StringVar(&opts.Badge, "badge", "", "Badge image URL")
+	fs.IntVar(&opts.Quality, "quality", 90, "Encoding quality")
+	fs.StringVar(&opts.Format, "format", "", "Output format: jpeg, jpegli, webp, avif or png (default jpegli)")
+	fs.IntVar(&opts.Width, "w", 0, "Output width")
+	fs.IntVar(&opts.Height, "h", 0, "Output height")
+	fs.StringVar(&opts.Fit, "fit", "", "Resize fit mode: cover, contain or fill")
+	return &ffcli.Command{
+		Name:       "image",
+		ShortUsage: "metatube image [flags] <provider> <id>",
+		ShortHelp:  "Save the processed image to file, - for stdout",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) != 2 {
+				return flag.ErrHelp
+			}
+			if *ratio >= 0 {
+				opts.Ratio = ratio
+			}
+			if *pos >= 0 {
+				opts.Position = pos
+			}
+			b, err := newBackend()
+			if err != nil {
+				return err
+			}
+			img, err := b.GetImage(ctx, client.ImageType(*typ), args[0], args[1], opts)
+			if err != nil {
+				return err
+			}
+			name := *out
+			switch name {
+			case "-":
+				_, err = stdout.Write(img.Data)
+				return err
+			case "":
+				ext := "jpg"
+				if _, sub, ok := strings.Cut(img.ContentType, "/"); ok && sub != "jpeg" {
+					ext = sub
+				}
+				name = fmt.Sprintf("%s-%s-%s.%s", args[0], args[1], *typ, ext)
+			}
+			if err = os.WriteFile(name, img.Data, 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintln(stderr, "saved", name)
+			return nil
+		},
+	}
+}
+
+func translateCommand() *ffcli.Command {
+	fs := flag.NewFlagSet("metatube translate", flag.ExitOnError)
+	from := fs.String("from", "auto", "Source language")
+	to := fs.String("to", "", "Target language")
+	engine := fs.String("engine", "", "Translation engine, e.g. google, deepl, openai")
+	config := configFlag{}
+	fs.Var(config, "config", "Engine config in key=value, can be repeated")
+	fs.StringVar(&globalConfig.Glossary, "glossary", "", "Path to JSON file of protected terms, in-process engine only")
+	fs.BoolVar(&globalConfig.GlossaryDB, "glossary-db", false, "Protect actor names from database, in-process engine only")
+	return &ffcli.Command{
+		Name:       "translate",
+		ShortUsage: "metatube translate -to <lang> -engine <engine> [flags] <text>",
+		ShortHelp:  "Translate text",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) != 1 || *to == "" || *engine == "" {
+				return flag.ErrHelp
+			}
+			b, err := newBackend()
+			if err != nil {
+				return err
+			}
+			result, err := b.Translate(ctx, args[0], *from, *to, *engine, url.Values(config))
+			if err != nil {
+				return err
+			}
+			return printTranslateResult(result)
+		},
+	}
+}
+
+// resolveProviderID returns the provider and id from args, which are
+// either <provider> <id> or a single URL resolved by resolve.
+func resolveProviderID(args []string, resolve func(string) (string, string, error)) (string, string, error) {
+	switch {
+	case len(args) == 2:
+		return args[0], args[1], nil
+	case len(args) == 1 && isURL(args[0]):
+		return resolve(args[0])
+	}
+	return "", "", flag.ErrHelp
+}
+
+// configFlag is a repeatable key=value flag.
+type configFlag url.Values
+
+func (f configFlag) String() string {
+	return url.Values(f).Encode()
+}
+
+func (f configFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("invalid config: %s", s)
+	}
+	url.Values(f).Add(key, value)
+	return nil
+}
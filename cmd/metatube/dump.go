@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/metatube-community/metatube-sdk-go/engine/dbengine"
+)
+
+func newDBEngine() (dbengine.DBEngine, error) {
+	if globalConfig.Remote != "" {
+		return nil, errors.New("export and import are not supported with remote server")
+	}
+	_, db, err := newEngine()
+	if err != nil {
+		return nil, err
+	}
+	return dbengine.New(db), nil
+}
+
+func exportCommand() *ffcli.Command {
+	fs := flag.NewFlagSet("metatube export", flag.ExitOnError)
+	out := fs.String("o", "-", "Output file, - for stdout, gzip-compressed if ends with .gz")
+	compress := fs.Bool("gzip", false, "Compress with gzip")
+	kinds := fs.String("kind", "", "Comma-separated kinds to export: movie, actor or review (default all)")
+	provider := fs.String("provider", "", "Export records of this provider only")
+	since := fs.String("since", "", "Export records updated since the date or RFC 3339 time")
+
+	return &ffcli.Command{
+		Name:       "export",
+		ShortUsage: "metatube export [flags]",
+		ShortHelp:  "Export the database as JSONL",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			opts := dbengine.ExportOptions{
+				Provider: *provider,
+				Gzip:     *compress || strings.HasSuffix(*out, ".gz"),
+			}
+			if *kinds != "" {
+				opts.Kinds = strings.Split(*kinds, ",")
+			}
+			if *since != "" {
+				var err error
+				if opts.UpdatedSince, err = parseSince(*since); err != nil {
+					return err
+				}
+			}
+			eng, err := newDBEngine()
+			if err != nil {
+				return err
+			}
+			w := stdout
+			if *out != "-" {
+				f, err := os.Create(*out)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			stats, err := eng.Export(w, opts)
+			if err != nil {
+				return err
+			}
+			printDumpStats("exported", stats)
+			return nil
+		},
+	}
+}
+
+func importCommand() *ffcli.Command {
+	fs := flag.NewFlagSet("metatube import", flag.ExitOnError)
+	conflict := fs.String("conflict", string(dbengine.ConflictNewer), "Conflict policy: newer, overwrite or skip")
+
+	return &ffcli.Command{
+		Name:       "import",
+		ShortUsage: "metatube import [flags] <file>",
+		ShortHelp:  "Import a JSONL export into the database, - for stdin",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return flag.ErrHelp
+			}
+			policy, err := dbengine.ParseConflictPolicy(*conflict)
+			if err != nil {
+				return err
+			}
+			eng, err := newDBEngine()
+			if err != nil {
+				return err
+			}
+			var r io.Reader = os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			stats, err := eng.Import(r, dbengine.ImportOptions{Conflict: policy})
+			if err != nil {
+				return err
+			}
+			printDumpStats("imported", stats)
+			return nil
+		},
+	}
+}
+
+// parseSince parses a date or an RFC 3339 time.
+func parseSince(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time: %s", s)
+	}
+	return t, nil
+}
+
+// printDumpStats prints to stderr, since stdout may be the export.
+func printDumpStats(verb string, stats *dbengine.DumpStats) {
+	fmt.Fprintf(stderr, "%s %d movies, %d actors, %d reviews, %d skipped\n",
+		verb, stats.Movies, stats.Actors, stats.Reviews, stats.Skipped)
+}
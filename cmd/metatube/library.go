@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/library"
+)
+
+func libraryCommand() *ffcli.Command {
+	fs := flag.NewFlagSet("metatube library", flag.ExitOnError)
+	exts := fs.String("ext", "", "Comma-separated extensions of movie files")
+	threshold := fs.Float64("threshold", library.DefaultMatchThreshold, "Min confidence of automatic matches")
+	concurrency := fs.Int("concurrency", library.DefaultConcurrency, "Number of files matched at once")
+
+	newLibrary := func() (*library.Library, *engine.Engine, error) {
+		if globalConfig.Remote != "" {
+			return nil, nil, errors.New("library is not supported with remote server")
+		}
+		app, db, err := newEngine()
+		if err != nil {
+			return nil, nil, err
+		}
+		opts := []library.Option{
+			library.WithMatchThreshold(*threshold),
+			library.WithConcurrency(*concurrency),
+		}
+		if *exts != "" {
+			opts = append(opts, library.WithExtensions(strings.Split(*exts, ",")...))
+		}
+		return library.New(db, app, opts...), app, nil
+	}
+
+	return &ffcli.Command{
+		Name:       "library",
+		ShortUsage: "metatube library [flags] <subcommand> [args...]",
+		ShortHelp:  "Scan and match local movie files",
+		FlagSet:    fs,
+		Subcommands: []*ffcli.Command{
+			libraryOrganizeCommand(newLibrary),
+			libraryArtworkCommand(newLibrary),
+			libraryWatchCommand(newLibrary),
+			{
+				Name:       "scan",
+				ShortUsage: "metatube library scan <dir>...",
+				ShortHelp:  "Scan directories and match movie files",
+				Exec: func(ctx context.Context, args []string) error {
+					if len(args) == 0 {
+						return flag.ErrHelp
+					}
+					lib, _, err := newLibrary()
+					if err != nil {
+						return err
+					}
+					report, err := lib.Scan(ctx, args...)
+					if err != nil {
+						return err
+					}
+					return printLibraryReport(report)
+				},
+			},
+			{
+				Name:       "review",
+				ShortUsage: "metatube library review",
+				ShortHelp:  "List ambiguous and unmatched files",
+				Exec: func(ctx context.Context, args []string) error {
+					lib, _, err := newLibrary()
+					if err != nil {
+						return err
+					}
+					files, err := lib.Files(library.StatusAmbiguous, library.StatusUnmatched)
+					if err != nil {
+						return err
+					}
+					return printLibraryReview(files)
+				},
+			},
+			{
+				Name:       "resolve",
+				ShortUsage: "metatube library resolve <path> <provider> <id>",
+				ShortHelp:  "Map a file to the movie manually",
+				Exec: func(ctx context.Context, args []string) error {
+					if len(args) != 3 {
+						return flag.ErrHelp
+					}
+					lib, _, err := newLibrary()
+					if err != nil {
+						return err
+					}
+					return lib.Resolve(args[0], args[1], args[2])
+				},
+			},
+			{
+				Name:       "undo",
+				ShortUsage: "metatube library undo",
+				ShortHelp:  "Undo the last organise",
+				Exec: func(ctx context.Context, args []string) error {
+					lib, _, err := newLibrary()
+					if err != nil {
+						return err
+					}
+					moves, err := lib.Undo(ctx)
+					if err != nil {
+						return err
+					}
+					// print reverted moves as planned.
+					for _, move := range moves {
+						move.Source, move.Target = move.Target, move.Source
+					}
+					return printLibraryPlan(&library.Plan{Moves: moves})
+				},
+			},
+		},
+		Exec: func(context.Context, []string) error {
+			return flag.ErrHelp
+		},
+	}
+}
+
+func libraryOrganizeCommand(newLibrary func() (*library.Library, *engine.Engine, error)) *ffcli.Command {
+	fs := flag.NewFlagSet("metatube library organize", flag.ExitOnError)
+	tmpl := fs.String("template", library.DefaultTemplate, "Path template of organised files")
+	dest := fs.String("dest", "", "Destination directory of organised files")
+	dryRun := fs.Bool("dry-run", false, "Print the plan without moving files")
+
+	return &ffcli.Command{
+		Name:       "organize",
+		ShortUsage: "metatube library organize [flags] -dest <dir>",
+		ShortHelp:  "Rename and move matched files with a path template",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if *dest == "" {
+				return flag.ErrHelp
+			}
+			t, err := library.ParseTemplate(*tmpl)
+			if err != nil {
+				return err
+			}
+			lib, app, err := newLibrary()
+			if err != nil {
+				return err
+			}
+			organizer := library.NewOrganizer(lib, app, t, *dest)
+			plan, err := organizer.Plan(ctx)
+			if err != nil {
+				return err
+			}
+			if !*dryRun {
+				if err = organizer.Apply(ctx, plan); err != nil {
+					return err
+				}
+			}
+			return printLibraryPlan(plan)
+		},
+	}
+}
+
+func libraryArtworkCommand(newLibrary func() (*library.Library, *engine.Engine, error)) *ffcli.Command {
+	fs := flag.NewFlagSet("metatube library artwork", flag.ExitOnError)
+	quality := fs.Int("quality", library.DefaultArtworkQuality, "JPEG quality of artworks")
+	extraFanart := fs.Int("extrafanart", library.DefaultExtraFanart, "Max number of preview images in extrafanart/")
+	force := fs.Bool("force", false, "Rewrite artworks even if up to date")
+
+	return &ffcli.Command{
+		Name:       "artwork",
+		ShortUsage: "metatube library artwork [flags]",
+		ShortHelp:  "Write poster, fanart and thumb images next to matched files",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			lib, app, err := newLibrary()
+			if err != nil {
+				return err
+			}
+			report, err := library.NewArtworkWriter(lib, app,
+				library.WithArtworkQuality(*quality),
+				library.WithExtraFanart(*extraFanart),
+				library.WithArtworkForce(*force),
+			).Write(ctx)
+			if err != nil {
+				return err
+			}
+			return printArtworkReport(report)
+		},
+	}
+}
+
+func libraryWatchCommand(newLibrary func() (*library.Library, *engine.Engine, error)) *ffcli.Command {
+	fs := flag.NewFlagSet("metatube library watch", flag.ExitOnError)
+	debounce := fs.Duration("debounce", library.DefaultDebounce, "Time a file must stay unchanged before matched")
+	retry := fs.Duration("retry", library.DefaultRetryInterval, "Interval of retrying failures")
+	webhook := fs.String("webhook", "", "URL to post watch events to")
+	writeNFO := fs.Bool("nfo", true, "Write NFO files of matched files")
+	writeArtwork := fs.Bool("artwork", true, "Write artworks of matched files")
+
+	return &ffcli.Command{
+		Name:       "watch",
+		ShortUsage: "metatube library watch [flags] <dir>...",
+		ShortHelp:  "Watch directories and match new movie files",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return flag.ErrHelp
+			}
+			lib, app, err := newLibrary()
+			if err != nil {
+				return err
+			}
+			opts := []library.WatchOption{
+				library.WithDebounce(*debounce),
+				library.WithRetryInterval(*retry),
+				library.WithWebhook(*webhook),
+				library.WithWatchLogOutput(stderr),
+			}
+			if *writeNFO {
+				backend := &localBackend{app: app}
+				opts = append(opts, library.WithWatchHook("nfo", func(ctx context.Context, file *library.File) error {
+					data, err := backend.GetMovieNFO(ctx, file.Provider, file.MovieID, true)
+					if err != nil {
+						return err
+					}
+					return library.WriteNFO(file, data)
+				}))
+			}
+			if *writeArtwork {
+				artwork := library.NewArtworkWriter(lib, app)
+				opts = append(opts, library.WithWatchHook("artwork", func(ctx context.Context, file *library.File) error {
+					return artwork.WriteFile(ctx, file, &library.ArtworkReport{})
+				}))
+			}
+			return library.NewWatcher(lib, args, opts...).Run(ctx)
+		},
+	}
+}
+
+func printArtworkReport(report *library.ArtworkReport) error {
+	return printOutput(report, func() error {
+		rows := [][]string{{"STATUS", "PATH"}}
+		for _, path := range report.Written {
+			rows = append(rows, []string{"written", path})
+		}
+		for _, path := range slices.Sorted(maps.Keys(report.Failed)) {
+			rows = append(rows, []string{"failed", path + ": " + report.Failed[path]})
+		}
+		if err := printTable(rows...); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(stdout, "\n%d written, %d skipped, %d failed\n",
+			len(report.Written), report.Skipped, len(report.Failed))
+		return err
+	})
+}
+
+func printLibraryPlan(plan *library.Plan) error {
+	return printOutput(plan, func() error {
+		rows := [][]string{{"SOURCE", "TARGET"}}
+		for _, move := range plan.Moves {
+			rows = append(rows, []string{move.Source, move.Target})
+		}
+		for _, path := range slices.Sorted(maps.Keys(plan.Failed)) {
+			rows = append(rows, []string{path, "error: " + plan.Failed[path]})
+		}
+		return printTable(rows...)
+	})
+}
+
+func printLibraryReport(report *library.Report) error {
+	return printOutput(report, func() error {
+		rows := [][]string{{"STATUS", "PATH", "NUMBER", "PART", "SUB", "PROVIDER", "ID", "CONFIDENCE"}}
+		for _, files := range [][]*library.File{report.Matched, report.Ambiguous, report.Unmatched} {
+			for _, file := range files {
+				rows = append(rows, libraryFileRow(file))
+			}
+		}
+		if err := printTable(rows...); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(stdout, "\n%d matched, %d ambiguous, %d unmatched, %d skipped, %d removed\n",
+			len(report.Matched), len(report.Ambiguous), len(report.Unmatched), report.Skipped, report.Removed)
+		return err
+	})
+}
+
+func printLibraryReview(files []*library.File) error {
+	return printOutput(files, func() error {
+		rows := [][]string{{"STATUS", "PATH", "NUMBER", "PART", "SUB", "PROVIDER", "ID", "CONFIDENCE"}}
+		for _, file := range files {
+			rows = append(rows, libraryFileRow(file))
+			// list candidates for manual review.
+			for _, c := range file.Candidates.Data() {
+				rows = append(rows, []string{"", "  " + truncate(c.Title, 60), c.Number, "", "",
+					c.Provider, c.ID, strconv.FormatFloat(c.Confidence, 'f', 2, 64)})
+			}
+		}
+		return printTable(rows...)
+	})
+}
+
+func libraryFileRow(file *library.File) []string {
+	var part, sub, confidence string
+	if file.Part > 0 {
+		part = strconv.Itoa(file.Part)
+	}
+	if file.Subtitle {
+		sub = "yes"
+	}
+	if file.Confidence > 0 {
+		confidence = strconv.FormatFloat(file.Confidence, 'f', 2, 64)
+	}
+	return []string{string(file.Status), file.Path, file.Number, part, sub,
+		file.Provider, file.MovieID, confidence}
+}
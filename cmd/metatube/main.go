@@ -0,0 +1,90 @@
+// Command metatube looks up metadata with an in-process engine,
+// or a remote server if -remote is set.
+package main
+
+import (
+	"context"
+	goerr "errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/peterbourgon/ff/v3"
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	V "github.com/metatube-community/metatube-sdk-go/internal/version"
+	_ "github.com/metatube-community/metatube-sdk-go/translate/baidu"
+	_ "github.com/metatube-community/metatube-sdk-go/translate/deepl"
+	_ "github.com/metatube-community/metatube-sdk-go/translate/google"
+	_ "github.com/metatube-community/metatube-sdk-go/translate/googlefree"
+	_ "github.com/metatube-community/metatube-sdk-go/translate/libretranslate"
+	_ "github.com/metatube-community/metatube-sdk-go/translate/ollama"
+	_ "github.com/metatube-community/metatube-sdk-go/translate/openai"
+)
+
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+var globalConfig = &struct {
+	Remote     string
+	Token      string
+	DSN        string
+	Output     string
+	Timeout    time.Duration
+	Glossary   string
+	GlossaryDB bool
+}{}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := newRootCommand().ParseAndRun(ctx, os.Args[1:]); err != nil {
+		if !goerr.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, "error:", err)
+		}
+		stop()
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *ffcli.Command {
+	fs := flag.NewFlagSet("metatube", flag.ExitOnError)
+	fs.StringVar(&globalConfig.Remote, "remote", "", "URL of remote server, use in-process engine if empty")
+	fs.StringVar(&globalConfig.Token, "token", "", "Token to access remote server")
+	fs.StringVar(&globalConfig.DSN, "dsn", "", "Database Service Name of in-process engine")
+	fs.StringVar(&globalConfig.Output, "output", outputTable, "Output mode: table, json or nfo")
+	fs.DurationVar(&globalConfig.Timeout, "timeout", 0, "Timeout per request of in-process engine")
+	version := fs.Bool("version", false, "Show version")
+
+	return &ffcli.Command{
+		Name:       "metatube",
+		ShortUsage: "metatube [flags] <subcommand> [flags] [args...]",
+		FlagSet:    fs,
+		Options:    []ff.Option{ff.WithEnvVarPrefix("METATUBE")},
+		Subcommands: []*ffcli.Command{
+			searchCommand(),
+			infoCommand(),
+			actorCommand(),
+			reviewsCommand(),
+			imageCommand(),
+			translateCommand(),
+			libraryCommand(),
+			exportCommand(),
+			importCommand(),
+			migrateCommand(),
+		},
+		Exec: func(context.Context, []string) error {
+			if *version {
+				fmt.Fprintln(stdout, V.BuildString())
+				return nil
+			}
+			return flag.ErrHelp
+		},
+	}
+}
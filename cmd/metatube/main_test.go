@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"github.com/metatube-community/metatube-sdk-go/database"
+	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/model"
+	mt "github.com/metatube-community/metatube-sdk-go/provider"
+)
+
+// newTestDB creates a database with a HEYZO movie, its images are
+// served by a local server.
+func newTestDB(t *testing.T) string {
+	buf := &bytes.Buffer{}
+	require.NoError(t, png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 800, 538))))
+	imageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	}))
+	t.Cleanup(imageServer.Close)
+
+	dsn := filepath.Join(t.TempDir(), "metatube.db")
+	db, err := database.Open(&database.Config{
+		DSN:                  dsn,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, engine.New(db).DBAutoMigrate(true))
+	require.NoError(t, db.Create(&model.MovieInfo{
+		ID:       "1234",
+		Number:   "HEYZO-1234",
+		Title:    "Test Movie",
+		Provider: "HEYZO",
+		Homepage: "https://www.heyzo.com/moviepages/1234/index.html",
+		ThumbURL: imageServer.URL + "/thumb.png",
+		CoverURL: imageServer.URL + "/cover.png",
+	}).Error)
+	require.NoError(t, db.Create(&model.MovieReviewInfo{
+		ID:       "1234",
+		Provider: "HEYZO",
+		Reviews: datatypes.NewJSONType([]*model.MovieReviewDetail{
+			{Author: "Reviewer", Comment: "Good", Score: 5},
+		}),
+	}).Error)
+	return dsn
+}
+
+// run runs the command with args, and returns its stdout.
+func run(t *testing.T, args ...string) (string, error) {
+	out := &bytes.Buffer{}
+	stdout, stderr = out, &bytes.Buffer{}
+	t.Cleanup(func() { stdout, stderr = os.Stdout, os.Stderr })
+	err := newRootCommand().ParseAndRun(context.Background(), args)
+	return out.String(), err
+}
+
+func TestLocalCommands(t *testing.T) {
+	dsn := newTestDB(t)
+
+	out, err := run(t, "-dsn", dsn, "search", "-provider", "HEYZO", "1234")
+	require.NoError(t, err)
+	assert.Contains(t, out, "HEYZO-1234")
+	assert.Contains(t, out, "Test Movie")
+
+	out, err = run(t, "-dsn", dsn, "-output", "json", "info", "HEYZO", "1234")
+	require.NoError(t, err)
+	info := &model.MovieInfo{}
+	require.NoError(t, json.Unmarshal([]byte(out), info))
+	assert.Equal(t, "HEYZO-1234", info.Number)
+
+	out, err = run(t, "-dsn", dsn, "-output", "nfo", "info", "HEYZO", "1234")
+	require.NoError(t, err)
+	assert.Contains(t, out, "<title>Test Movie</title>")
+
+	out, err = run(t, "-dsn", dsn, "reviews", "HEYZO", "1234")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Good")
+}
+
+func TestImageCommand(t *testing.T) {
+	dsn := newTestDB(t)
+
+	out, err := run(t, "-dsn", dsn, "image", "-type", "thumb", "-format", "png", "-w", "100", "-o", "-", "HEYZO", "1234")
+	require.NoError(t, err)
+	cfg, err := png.DecodeConfig(bytes.NewReader([]byte(out)))
+	require.NoError(t, err)
+	assert.Equal(t, 100, cfg.Width)
+
+	// never upscaled beyond the source size, same as the server.
+	out, err = run(t, "-dsn", dsn, "image", "-type", "thumb", "-format", "png", "-w", "100000", "-o", "-", "HEYZO", "1234")
+	require.NoError(t, err)
+	cfg, err = png.DecodeConfig(bytes.NewReader([]byte(out)))
+	require.NoError(t, err)
+	assert.Equal(t, 800, cfg.Width)
+
+	_, err = run(t, "-dsn", dsn, "image", "-type", "thumb", "-fit", "unknown", "-w", "100", "-o", "-", "HEYZO", "1234")
+	assert.Error(t, err)
+}
+
+func TestResolveNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	_, err := run(t, "-remote", server.URL, "info", "https://example.com/movie/1")
+	assert.ErrorIs(t, err, mt.ErrInfoNotFound)
+	_, err = run(t, "-remote", server.URL, "actor", "https://example.com/actor/1")
+	assert.ErrorIs(t, err, mt.ErrInfoNotFound)
+}
+
+func TestExportImport(t *testing.T) {
+	dsn := newTestDB(t)
+	dump := filepath.Join(t.TempDir(), "dump.jsonl.gz")
+	_, err := run(t, "-dsn", dsn, "export", "-o", dump, "-provider", "heyzo")
+	require.NoError(t, err)
+
+	// import into an empty database.
+	target := filepath.Join(t.TempDir(), "target.db")
+	_, err = run(t, "-dsn", target, "import", dump)
+	require.NoError(t, err)
+	out, err := run(t, "-dsn", target, "-output", "json", "info", "HEYZO", "1234")
+	require.NoError(t, err)
+	info := &model.MovieInfo{}
+	require.NoError(t, json.Unmarshal([]byte(out), info))
+	assert.Equal(t, "Test Movie", info.Title)
+	out, err = run(t, "-dsn", target, "reviews", "HEYZO", "1234")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Good")
+
+	_, err = run(t, "-dsn", target, "import", "-conflict", "unknown", dump)
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/metatube-community/metatube-sdk-go/database/migrate"
+)
+
+func newMigrator() (*migrate.Migrator, error) {
+	if globalConfig.Remote != "" {
+		return nil, errors.New("migrate is not supported with remote server")
+	}
+	// open the DB without the engine, which migrates the sqlite DB and
+	// refuses future schemas.
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db)
+}
+
+func migrateCommand() *ffcli.Command {
+	downFlags := flag.NewFlagSet("metatube migrate down", flag.ExitOnError)
+	steps := downFlags.Int("steps", 1, "Number of migrations to revert")
+
+	return &ffcli.Command{
+		Name:       "migrate",
+		ShortUsage: "metatube migrate <subcommand>",
+		ShortHelp:  "Manage database schema migrations",
+		Subcommands: []*ffcli.Command{
+			{
+				Name:       "status",
+				ShortUsage: "metatube migrate status",
+				ShortHelp:  "Show applied and pending migrations",
+				Exec: func(context.Context, []string) error {
+					m, err := newMigrator()
+					if err != nil {
+						return err
+					}
+					statuses, err := m.Status()
+					if err != nil {
+						return err
+					}
+					return printMigrationStatus(statuses)
+				},
+			},
+			{
+				Name:       "up",
+				ShortUsage: "metatube migrate up",
+				ShortHelp:  "Apply all pending migrations",
+				Exec: func(context.Context, []string) error {
+					m, err := newMigrator()
+					if err != nil {
+						return err
+					}
+					if err = m.Up(); err != nil {
+						return err
+					}
+					return printMigrationVersion(m)
+				},
+			},
+			{
+				Name:       "down",
+				ShortUsage: "metatube migrate down [-steps n]",
+				ShortHelp:  "Revert the latest applied migrations",
+				FlagSet:    downFlags,
+				Exec: func(context.Context, []string) error {
+					if *steps < 1 {
+						return fmt.Errorf("invalid steps: %d", *steps)
+					}
+					m, err := newMigrator()
+					if err != nil {
+						return err
+					}
+					if err = m.Down(*steps); err != nil {
+						return err
+					}
+					return printMigrationVersion(m)
+				},
+			},
+		},
+		Exec: func(context.Context, []string) error {
+			return flag.ErrHelp
+		},
+	}
+}
+
+func printMigrationStatus(statuses []*migrate.Status) error {
+	return printOutput(statuses, func() error {
+		rows := [][]string{{"VERSION", "NAME", "STATUS", "APPLIED AT"}}
+		for _, status := range statuses {
+			state, appliedAt := "pending", ""
+			if status.Applied {
+				state = "applied"
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Unknown {
+				state = "unknown"
+			}
+			rows = append(rows, []string{strconv.Itoa(status.Version), status.Name, state, appliedAt})
+		}
+		return printTable(rows...)
+	})
+}
+
+func printMigrationVersion(m *migrate.Migrator) error {
+	version, err := m.Version()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "schema version: %d\n", version)
+	return nil
+}
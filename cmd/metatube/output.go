@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/datatypes"
+
+	"github.com/metatube-community/metatube-sdk-go/client"
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputNFO   = "nfo"
+)
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// printTable prints rows as aligned columns, the first row is the header.
+func printTable(rows ...[]string) error {
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// printFields prints name and value pairs, empty values are omitted.
+func printFields(fields ...[2]string) error {
+	rows := make([][]string, 0, len(fields))
+	for _, field := range fields {
+		if field[1] != "" {
+			rows = append(rows, []string{field[0] + ":", field[1]})
+		}
+	}
+	return printTable(rows...)
+}
+
+func printOutput(v any, table func() error) error {
+	switch globalConfig.Output {
+	case outputJSON:
+		return printJSON(v)
+	case outputTable, "":
+		return table()
+	}
+	return fmt.Errorf("unsupported output mode: %s", globalConfig.Output)
+}
+
+func printMovieSearchResults(results []*model.MovieSearchResult) error {
+	return printOutput(results, func() error {
+		rows := [][]string{{"PROVIDER", "ID", "NUMBER", "TITLE", "RELEASE DATE"}}
+		for _, result := range results {
+			rows = append(rows, []string{result.Provider, result.ID, result.Number,
+				truncate(result.Title, 60), formatDate(result.ReleaseDate)})
+		}
+		return printTable(rows...)
+	})
+}
+
+func printActorSearchResults(results []*model.ActorSearchResult) error {
+	return printOutput(results, func() error {
+		rows := [][]string{{"PROVIDER", "ID", "NAME", "ALIASES"}}
+		for _, result := range results {
+			rows = append(rows, []string{result.Provider, result.ID, result.Name,
+				truncate(strings.Join(result.Aliases, ", "), 60)})
+		}
+		return printTable(rows...)
+	})
+}
+
+func printMovieInfo(info *model.MovieInfo) error {
+	return printOutput(info, func() error {
+		return printFields(
+			[2]string{"Provider", info.Provider},
+			[2]string{"ID", info.ID},
+			[2]string{"Number", info.Number},
+			[2]string{"Title", info.Title},
+			[2]string{"Director", info.Director},
+			[2]string{"Actors", strings.Join(info.Actors, ", ")},
+			[2]string{"Maker", info.Maker},
+			[2]string{"Label", info.Label},
+			[2]string{"Series", info.Series},
+			[2]string{"Genres", strings.Join(info.Genres, ", ")},
+			[2]string{"Runtime", formatRuntime(info.Runtime)},
+			[2]string{"Release Date", formatDate(info.ReleaseDate)},
+			[2]string{"Score", formatScore(info.Score)},
+			[2]string{"Homepage", info.Homepage},
+			[2]string{"Cover", info.CoverURL},
+			[2]string{"Summary", truncate(info.Summary, 200)},
+		)
+	})
+}
+
+func printActorInfo(info *model.ActorInfo) error {
+	return printOutput(info, func() error {
+		height := ""
+		if info.Height > 0 {
+			height = fmt.Sprintf("%dcm", info.Height)
+		}
+		return printFields(
+			[2]string{"Provider", info.Provider},
+			[2]string{"ID", info.ID},
+			[2]string{"Name", info.Name},
+			[2]string{"Aliases", strings.Join(info.Aliases, ", ")},
+			[2]string{"Birthday", formatDate(info.Birthday)},
+			[2]string{"Debut Date", formatDate(info.DebutDate)},
+			[2]string{"Nationality", info.Nationality},
+			[2]string{"Height", height},
+			[2]string{"Measurements", info.Measurements},
+			[2]string{"Cup Size", info.CupSize},
+			[2]string{"Blood Type", info.BloodType},
+			[2]string{"Homepage", info.Homepage},
+			[2]string{"Summary", truncate(info.Summary, 200)},
+		)
+	})
+}
+
+func printMovieReviews(reviews []*model.MovieReviewDetail) error {
+	return printOutput(reviews, func() error {
+		rows := [][]string{{"DATE", "SCORE", "AUTHOR", "TITLE", "COMMENT"}}
+		for _, review := range reviews {
+			rows = append(rows, []string{formatDate(review.Date), formatScore(review.Score),
+				review.Author, truncate(review.Title, 30), truncate(review.Comment, 80)})
+		}
+		return printTable(rows...)
+	})
+}
+
+func printTranslateResult(result *client.TranslateResult) error {
+	return printOutput(result, func() error {
+		_, err := fmt.Fprintln(stdout, result.Text)
+		return err
+	})
+}
+
+func formatDate(date datatypes.Date) string {
+	if t := time.Time(date); !t.IsZero() {
+		return t.Format(time.DateOnly)
+	}
+	return ""
+}
+
+func formatRuntime(minutes int) string {
+	if minutes > 0 {
+		return fmt.Sprintf("%dmin", minutes)
+	}
+	return ""
+}
+
+func formatScore(score float64) string {
+	if score > 0 {
+		return fmt.Sprintf("%.1f", score)
+	}
+	return ""
+}
+
+// truncate truncates s to at most n runes, newlines are replaced so
+// that it fits in a single table cell.
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
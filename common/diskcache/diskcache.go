@@ -0,0 +1,272 @@
+package diskcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const tempFilePrefix = ".tmp-"
+
+// Cache is an on-disk LRU cache with size limit and TTL.
+type Cache struct {
+	dir     string
+	maxSize int64
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List // front is the most recently used.
+	entries map[string]*list.Element
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+type entry struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// New creates a cache in dir, existing files in dir are loaded. The
+// total size of files is limited to maxSize bytes if maxSize > 0, and
+// files expire after ttl if ttl > 0.
+func New(dir string, maxSize int64, ttl time.Duration) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		dir:     dir,
+		maxSize: maxSize,
+		ttl:     ttl,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		stopCh:  make(chan struct{}),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Cache) load() error {
+	var entries []*entry
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), tempFilePrefix) {
+			// remove partially written files.
+			return os.Remove(path)
+		}
+		if path != c.path(d.Name()) {
+			return nil // not a cache file.
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, &entry{
+			name:    d.Name(),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// the least recently modified files are evicted first.
+	slices.SortFunc(entries, func(a, b *entry) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range entries {
+		c.entries[e.name] = c.lru.PushFront(e)
+		c.size += e.size
+	}
+	c.evict()
+	return nil
+}
+
+// Get returns the cached data of key.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	name := hashKey(key)
+
+	c.mu.Lock()
+	elem, ok := c.entries[name]
+	if !ok {
+		c.mu.Unlock()
+		return nil, false
+	}
+	if c.expired(elem.Value.(*entry)) {
+		c.remove(elem)
+		c.mu.Unlock()
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	c.mu.Unlock()
+
+	// files are replaced atomically by Set, so reading
+	// them without holding the lock is safe.
+	data, err := os.ReadFile(c.path(name))
+	if err != nil {
+		// the file may be removed externally, but do not
+		// remove the entry if it is replaced concurrently.
+		c.mu.Lock()
+		if cur, ok := c.entries[name]; ok && cur == elem {
+			c.remove(elem)
+		}
+		c.mu.Unlock()
+		return nil, false
+	}
+	return data, true
+}
+
+// Set stores data of key, least recently used files are evicted
+// if the size limit is exceeded.
+func (c *Cache) Set(key string, data []byte) error {
+	name := hashKey(key)
+	path := c.path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// write to a temp file and rename, so that readers
+	// never see partially written files.
+	f, err := os.CreateTemp(filepath.Dir(path), tempFilePrefix+"*")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if elem, ok := c.entries[name]; ok {
+		c.size -= elem.Value.(*entry).size
+		c.lru.Remove(elem)
+	}
+	c.entries[name] = c.lru.PushFront(&entry{
+		name:    name,
+		size:    int64(len(data)),
+		modTime: time.Now(),
+	})
+	c.size += int64(len(data))
+	c.evict()
+	return nil
+}
+
+// Delete removes key from cache.
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[hashKey(key)]; ok {
+		c.remove(elem)
+	}
+}
+
+// Prune removes all expired files.
+func (c *Cache) Prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if c.expired(elem.Value.(*entry)) {
+			c.remove(elem)
+		}
+		elem = prev
+	}
+}
+
+// Start prunes expired files every interval until Stop is called,
+// it returns immediately if the cache has no TTL.
+func (c *Cache) Start(interval time.Duration) {
+	if c.ttl <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.Prune()
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// Stop stops the pruning started by Start.
+func (c *Cache) Stop() {
+	c.stopOnce.Do(func() { close(c.stopCh) })
+}
+
+// Size returns the total size of cached files.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Len returns the number of cached files.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *Cache) evict() {
+	for c.maxSize > 0 && c.size > c.maxSize {
+		elem := c.lru.Back()
+		if elem == nil {
+			break
+		}
+		c.remove(elem)
+	}
+}
+
+func (c *Cache) remove(elem *list.Element) {
+	e := c.lru.Remove(elem).(*entry)
+	delete(c.entries, e.name)
+	c.size -= e.size
+	// ignore errors, the file will be reloaded and evicted next time.
+	_ = os.Remove(c.path(e.name))
+}
+
+func (c *Cache) expired(e *entry) bool {
+	return c.ttl > 0 && time.Since(e.modTime) > c.ttl
+}
+
+// path shards files into subdirectories by the hash prefix.
+func (c *Cache) path(name string) string {
+	if len(name) < 2 {
+		return filepath.Join(c.dir, name)
+	}
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
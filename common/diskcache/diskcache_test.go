@@ -0,0 +1,103 @@
+package diskcache
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, 25, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set("a", bytes.Repeat([]byte("a"), 10)))
+	require.NoError(t, c.Set("b", bytes.Repeat([]byte("b"), 10)))
+
+	data, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, bytes.Repeat([]byte("a"), 10), data)
+
+	// "b" is the least recently used.
+	require.NoError(t, c.Set("c", bytes.Repeat([]byte("c"), 10)))
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, int64(20), c.Size())
+
+	// overwrite.
+	require.NoError(t, c.Set("a", []byte("x")))
+	data, _ = c.Get("a")
+	assert.Equal(t, []byte("x"), data)
+	assert.Equal(t, int64(11), c.Size())
+
+	c.Delete("a")
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+
+	// reload from disk.
+	c, err = New(dir, 25, 0)
+	require.NoError(t, err)
+	data, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, bytes.Repeat([]byte("c"), 10), data)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestCacheTTL(t *testing.T) {
+	c, err := New(t.TempDir(), 0, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set("a", []byte("a")))
+	require.NoError(t, c.Set("b", []byte("b")))
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	time.Sleep(100 * time.Millisecond)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+
+	c.Prune()
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, int64(0), c.Size())
+}
+
+func TestCacheStart(t *testing.T) {
+	c, err := New(t.TempDir(), 0, 10*time.Millisecond)
+	require.NoError(t, err)
+	go c.Start(10 * time.Millisecond)
+	defer c.Stop()
+
+	require.NoError(t, c.Set("a", []byte("a")))
+	assert.Eventually(t, func() bool {
+		return c.Len() == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCacheConcurrentSet(t *testing.T) {
+	c, err := New(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				require.NoError(t, c.Set("a", []byte("a")))
+				if data, ok := c.Get("a"); ok {
+					assert.Equal(t, []byte("a"), data)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, c.Len())
+}
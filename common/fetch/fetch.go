@@ -41,6 +41,11 @@ type Config struct {
 	// Skip TLS verification. Applies only
 	// to *http.Transport based transport.
 	SkipVerify bool
+
+	// Refuse to connect to loopback, private and other
+	// non-public addresses, e.g., for user-supplied URLs.
+	// Ignored if a custom Transport is set.
+	DisallowPrivateAddrs bool
 }
 
 type Fetcher struct {
@@ -78,7 +83,7 @@ func Default(cfg *Config) *Fetcher {
 		RetryWaitMin: 1 * time.Second,
 		RetryWaitMax: 3 * time.Second,
 		RetryMax:     3,
-		CheckRetry:   retryablehttp.DefaultRetryPolicy,
+		CheckRetry:   checkRetry,
 		Backoff:      retryablehttp.DefaultBackoff,
 	}
 	if cfg.Timeout > time.Second {
@@ -86,6 +91,8 @@ func Default(cfg *Config) *Fetcher {
 	}
 	if cfg.Transport != nil {
 		c.HTTPClient.Transport = cfg.Transport
+	} else if cfg.DisallowPrivateAddrs {
+		c.HTTPClient.Transport = guardedTransport()
 	}
 	if cfg.SkipVerify {
 		if transport, ok := c.HTTPClient.Transport.(*http.Transport); ok {
@@ -99,6 +106,20 @@ func Default(cfg *Config) *Fetcher {
 	return New(c.StandardClient(), cfg)
 }
 
+// Guarded returns a new fetcher with the same config, but it refuses
+// to connect to private addresses, see Config.DisallowPrivateAddrs.
+func (f *Fetcher) Guarded() *Fetcher {
+	cfg := *f.config /* clone */
+	cfg.Transport = nil
+	cfg.RandomUserAgent = false
+	cfg.DisallowPrivateAddrs = true
+	g := Default(&cfg)
+	// keep the original status check and timeout.
+	g.config.RaiseForStatus = f.config.RaiseForStatus
+	g.client.Timeout = f.client.Timeout
+	return g
+}
+
 func (f *Fetcher) Fetch(url string) (resp *http.Response, err error) {
 	return f.Get(url)
 }
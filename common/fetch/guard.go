@@ -0,0 +1,132 @@
+package fetch
+
+import (
+	"context"
+	goerr "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/metatube-community/metatube-sdk-go/errors"
+)
+
+var ErrPrivateAddress = errors.New(http.StatusForbidden, "private address is not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range, RFC 6598.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPrivateAddr reports whether addr is a loopback, private, link-local
+// or any other non-public address.
+func IsPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// CheckPublicHost resolves host and returns ErrPrivateAddress if any of
+// its addresses is not public.
+func CheckPublicHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if IsPrivateAddr(addr) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// guardedTransport returns a transport that refuses to connect to
+// private addresses. The check is done at dial time, i.e. after DNS
+// resolution, so it cannot be bypassed by DNS rebinding.
+//
+// Proxies from the environment are still honored. Since the proxy
+// dials the destination on our behalf, the destination is resolved
+// and checked beforehand, and the proxy itself is dialed unguarded,
+// as it is configured by the operator.
+func guardedTransport() *http.Transport {
+	return newGuardedTransport(http.ProxyFromEnvironment)
+}
+
+func newGuardedTransport(proxy func(*http.Request) (*url.URL, error)) *http.Transport {
+	var proxyAddrs sync.Map // canonical addresses of used proxies.
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	guardedDialer := &net.Dialer{
+		Timeout:   dialer.Timeout,
+		KeepAlive: dialer.KeepAlive,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("parse address %s: %w", address, err)
+			}
+			if IsPrivateAddr(addrPort.Addr()) {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		u, err := proxy(req)
+		if err != nil || u == nil {
+			return u, err
+		}
+		if err = CheckPublicHost(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+		proxyAddrs.Store(canonicalAddr(u), struct{}{})
+		return u, nil
+	}
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if _, ok := proxyAddrs.Load(address); ok {
+			return dialer.DialContext(ctx, network, address)
+		}
+		return guardedDialer.DialContext(ctx, network, address)
+	}
+	return transport
+}
+
+// canonicalAddr returns the host:port address of the proxy url, the
+// same one the transport dials.
+func canonicalAddr(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// checkRetry never retries requests refused by the guarded transport.
+func checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if goerr.Is(err, ErrPrivateAddress) {
+		return false, err
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
@@ -0,0 +1,84 @@
+package fetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPrivateAddr(t *testing.T) {
+	for _, unit := range []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"8.8.8.8", false},
+		{"2001:4860:4860::8888", false},
+	} {
+		assert.Equal(t, unit.want, IsPrivateAddr(netip.MustParseAddr(unit.addr)), unit.addr)
+	}
+}
+
+func TestDisallowPrivateAddrs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	_, err := Default(&Config{DisallowPrivateAddrs: true}).Fetch(srv.URL)
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+
+	_, err = Default(nil).Fetch(srv.URL)
+	assert.NoError(t, err)
+}
+
+func TestGuardedTransportProxy(t *testing.T) {
+	// the proxy runs on loopback, which is set by the operator.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proxied", r.URL.String())
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	client := &http.Client{Transport: newGuardedTransport(http.ProxyURL(proxyURL))}
+
+	// public destinations are dialed through the proxy.
+	resp, err := client.Get("http://93.184.215.14/")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, "http://93.184.215.14/", resp.Header.Get("X-Proxied"))
+	}
+
+	// private destinations are refused before reaching the proxy.
+	_, err = client.Get("http://127.0.0.1:8080/")
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
+
+func TestGuarded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Referer", r.Referer())
+	}))
+	defer srv.Close()
+
+	f := Default(&Config{Referer: "https://example.com/"})
+	resp, err := f.Fetch(srv.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, "https://example.com/", resp.Header.Get("X-Referer"))
+	}
+
+	_, err = f.Guarded().Fetch(srv.URL)
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
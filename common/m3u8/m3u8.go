@@ -36,3 +36,37 @@ func ParseBestMediaURI(reader io.Reader) (string, m3u8.ListType, error) {
 		return "", 0, errors.New("bad list type")
 	}
 }
+
+// RewriteMediaURIs rewrites all segment, key and map URIs of the media
+// playlist, and returns the encoded playlist.
+func RewriteMediaURIs(reader io.Reader, rewrite func(uri string) string) ([]byte, error) {
+	playList, listType, err := m3u8.DecodeFrom(reader, true)
+	if err != nil {
+		return nil, err
+	}
+	if listType != m3u8.MEDIA {
+		return nil, errors.New("not a media playlist")
+	}
+	mediaPL := playList.(*m3u8.MediaPlaylist)
+	rewriteKey := func(key *m3u8.Key) {
+		if key != nil && key.URI != "" {
+			key.URI = rewrite(key.URI)
+		}
+	}
+	rewriteMap := func(m *m3u8.Map) {
+		if m != nil && m.URI != "" {
+			m.URI = rewrite(m.URI)
+		}
+	}
+	rewriteKey(mediaPL.Key)
+	rewriteMap(mediaPL.Map)
+	for _, segment := range mediaPL.Segments {
+		if segment == nil {
+			continue // segments are pre-allocated.
+		}
+		segment.URI = rewrite(segment.URI)
+		rewriteKey(segment.Key)
+		rewriteMap(segment.Map)
+	}
+	return mediaPL.Encode().Bytes(), nil
+}
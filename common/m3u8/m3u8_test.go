@@ -57,3 +57,18 @@ func TestParseBestMediaURI(t *testing.T) {
 	require.Equal(t, "", url)
 	require.Equal(t, m3u8.MEDIA, typ)
 }
+
+func TestRewriteMediaURIs(t *testing.T) {
+	buf := bytes.NewReader(unsafe.Slice(unsafe.StringData(m3u8Sample2), len(m3u8Sample2)))
+	data, err := RewriteMediaURIs(buf, func(uri string) string {
+		return "/proxy?url=" + uri
+	})
+	require.NoError(t, err)
+	require.Contains(t, string(data), "/proxy?url=0640/06400.ts\n")
+	require.Contains(t, string(data), "/proxy?url=0640/0640535.ts\n")
+	require.Contains(t, string(data), "#EXT-X-ENDLIST")
+
+	buf = bytes.NewReader(unsafe.Slice(unsafe.StringData(m3u8Sample1), len(m3u8Sample1)))
+	_, err = RewriteMediaURIs(buf, func(uri string) string { return uri })
+	require.Error(t, err)
+}
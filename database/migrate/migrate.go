@@ -0,0 +1,324 @@
+// Package migrate applies versioned schema migrations, which are SQL
+// files embedded per database driver, and records the applied versions
+// in the schema_migrations table.
+package migrate
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/metatube-community/metatube-sdk-go/database"
+)
+
+const SchemaMigrationsTableName = "schema_migrations"
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+// ErrFutureSchema is returned if the database is migrated by a newer
+// version, which this version must not run against.
+var ErrFutureSchema = errors.New("database schema is newer than supported")
+
+// migrationFileRegex matches migration files, e.g. 0001_metadata.up.sql.
+var migrationFileRegex = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change with its revert.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is the applied status of a migration.
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Unknown is true if the migration is applied by a newer version.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// schemaMigration is the DB model of applied migrations.
+type schemaMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+func (*schemaMigration) TableName() string {
+	return SchemaMigrationsTableName
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []*Migration
+}
+
+// New returns a migrator with the embedded migrations of the driver.
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(db.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func loadMigrations(driver string) ([]*Migration, error) {
+	switch driver {
+	case database.Sqlite, database.Postgres:
+	default:
+		return nil, fmt.Errorf("unsupported DB type: %s", driver)
+	}
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationsFS, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		m := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("invalid migration file: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		data, err := fs.ReadFile(migrationsFS, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		} else if migration.Name != m[2] {
+			return nil, fmt.Errorf("conflicting migration names of version %d", version)
+		}
+		if m[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d requires both up and down steps", migration.Version)
+		}
+		migrations = append(migrations, migration)
+	}
+	slices.SortFunc(migrations, func(a, b *Migration) int { return a.Version - b.Version })
+	return migrations, nil
+}
+
+// Migrations returns the known migrations in version order.
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+// Latest returns the latest known version.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) ensureTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS ` + SchemaMigrationsTableName + ` (
+	  version integer PRIMARY KEY,
+	  name text NOT NULL,
+	  applied_at timestamp NOT NULL
+	)`).Error
+}
+
+// applied returns the applied migrations, none if the table does not
+// exist yet. The table is created by Up only, so that read-only checks
+// never write the database.
+func (m *Migrator) applied() (map[int]*schemaMigration, error) {
+	if !m.db.Migrator().HasTable(SchemaMigrationsTableName) {
+		return map[int]*schemaMigration{}, nil
+	}
+	var rows []*schemaMigration
+	if err := m.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]*schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Version returns the latest applied version, 0 if none.
+func (m *Migrator) Version() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+	var version int
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// Status lists the known migrations, and the unknown ones applied by
+// newer versions.
+func (m *Migrator) Status() ([]*Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	var statuses []*Status
+	for _, migration := range m.migrations {
+		status := &Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			status.Applied, status.AppliedAt = true, &row.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range applied {
+		statuses = append(statuses, &Status{
+			Version:   row.Version,
+			Name:      row.Name,
+			Applied:   true,
+			AppliedAt: &row.AppliedAt,
+			Unknown:   true,
+		})
+	}
+	slices.SortFunc(statuses, func(a, b *Status) int { return a.Version - b.Version })
+	return statuses, nil
+}
+
+// Check returns ErrFutureSchema if the database has migrations unknown
+// to this version. Pending migrations are not errors, since they are
+// applied only if enabled.
+func (m *Migrator) Check() error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	return checkUnknown(statuses)
+}
+
+func checkUnknown(statuses []*Status) error {
+	for _, status := range statuses {
+		if status.Unknown {
+			return fmt.Errorf("%w: unknown migration %d_%s", ErrFutureSchema, status.Version, status.Name)
+		}
+	}
+	return nil
+}
+
+// Up applies all pending migrations in version order, each migration
+// is applied in a transaction.
+func (m *Migrator) Up() error {
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if err = checkUnknown(statuses); err != nil {
+		return err
+	}
+	for i, migration := range m.migrations {
+		if statuses[i].Applied {
+			continue
+		}
+		if err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Up); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		}); err != nil {
+			return fmt.Errorf("migrate up %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// Down reverts the latest n applied migrations.
+func (m *Migrator) Down(n int) error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	for i := len(statuses) - 1; i >= 0 && n > 0; i-- {
+		status := statuses[i]
+		if !status.Applied {
+			continue
+		}
+		if status.Unknown {
+			return fmt.Errorf("%w: unknown migration %d_%s", ErrFutureSchema, status.Version, status.Name)
+		}
+		idx := slices.IndexFunc(m.migrations, func(migration *Migration) bool {
+			return migration.Version == status.Version
+		})
+		migration := m.migrations[idx]
+		if err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, "version = ?", migration.Version).Error
+		}); err != nil {
+			return fmt.Errorf("migrate down %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		n--
+	}
+	return nil
+}
+
+// execScript executes the statements of the SQL script one by one,
+// since prepared statements cannot contain multiple statements.
+func execScript(tx *gorm.DB, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements splits the script by semicolons at line ends. Trigger
+// bodies are kept whole until their END line.
+func splitStatements(script string) []string {
+	var (
+		stmts   []string
+		current []string
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if len(current) == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+		current = append(current, line)
+		inTrigger := strings.HasPrefix(strings.ToUpper(strings.TrimSpace(current[0])), "CREATE TRIGGER")
+		if (inTrigger && strings.EqualFold(trimmed, "END;")) ||
+			(!inTrigger && strings.HasSuffix(trimmed, ";")) {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(strings.Join(current, "\n")), ";"))
+			current = nil
+		}
+	}
+	if stmt := strings.TrimSpace(strings.Join(current, "\n")); stmt != "" {
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
@@ -0,0 +1,152 @@
+package migrate_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/metatube-community/metatube-sdk-go/database"
+	"github.com/metatube-community/metatube-sdk-go/database/migrate"
+	"github.com/metatube-community/metatube-sdk-go/library"
+	"github.com/metatube-community/metatube-sdk-go/model"
+	"github.com/metatube-community/metatube-sdk-go/route/auth"
+)
+
+// models are all the DB models, the migrations must create all their
+// columns.
+var models = []any{
+	&model.MovieInfo{},
+	&model.ActorInfo{},
+	&model.MovieReviewInfo{},
+	&model.MovieActor{},
+	&auth.APIToken{},
+	&library.File{},
+	&library.Move{},
+	&library.Failure{},
+}
+
+func newTestMigrator(t *testing.T) (*migrate.Migrator, *gorm.DB) {
+	db, err := database.Open(&database.Config{
+		DSN:                  filepath.Join(t.TempDir(), "metatube.db"),
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	m, err := migrate.New(db)
+	require.NoError(t, err)
+	return m, db
+}
+
+func TestMigrateModels(t *testing.T) {
+	m, db := newTestMigrator(t)
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Check())
+
+	for _, v := range models {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(v))
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			assert.True(t, db.Migrator().HasColumn(v, field.DBName),
+				"%s.%s", stmt.Schema.Table, field.DBName)
+		}
+		// the migrated schema must be usable by the model.
+		assert.NoError(t, db.Model(v).Limit(1).Find(v).Error, stmt.Schema.Table)
+	}
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	m, db := newTestMigrator(t)
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, len(m.Migrations()))
+	for _, status := range statuses {
+		assert.False(t, status.Applied)
+	}
+	// read-only checks do not write the database.
+	require.NoError(t, m.Check())
+	version, err := m.Version()
+	require.NoError(t, err)
+	assert.Zero(t, version)
+	assert.False(t, db.Migrator().HasTable(migrate.SchemaMigrationsTableName))
+
+	require.NoError(t, m.Up())
+	version, err = m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, m.Latest(), version)
+	// up is idempotent.
+	require.NoError(t, m.Up())
+
+	require.NoError(t, m.Down(1))
+	version, err = m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, m.Migrations()[len(m.Migrations())-2].Version, version)
+
+	require.NoError(t, m.Down(len(m.Migrations())))
+	version, err = m.Version()
+	require.NoError(t, err)
+	assert.Zero(t, version)
+	for _, v := range models {
+		assert.False(t, db.Migrator().HasTable(v))
+	}
+
+	require.NoError(t, m.Up())
+	for _, v := range models {
+		assert.True(t, db.Migrator().HasTable(v))
+	}
+}
+
+func TestMigrateFutureSchema(t *testing.T) {
+	m, db := newTestMigrator(t)
+	require.NoError(t, m.Up())
+
+	require.NoError(t, db.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, CURRENT_TIMESTAMP)`,
+		m.Latest()+1, "future").Error)
+
+	assert.ErrorIs(t, m.Check(), migrate.ErrFutureSchema)
+	assert.ErrorIs(t, m.Up(), migrate.ErrFutureSchema)
+	assert.ErrorIs(t, m.Down(1), migrate.ErrFutureSchema)
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	last := statuses[len(statuses)-1]
+	assert.True(t, last.Unknown)
+	assert.Equal(t, "future", last.Name)
+}
+
+func TestMigrateMovieActors(t *testing.T) {
+	m, db := newTestMigrator(t)
+	require.NoError(t, m.Up())
+	// revert the movie actors migration to backfill the links.
+	require.NoError(t, m.Down(1))
+	require.False(t, db.Migrator().HasTable(&model.MovieActor{}))
+
+	require.NoError(t, db.Create(&model.ActorInfo{
+		ID:       "1",
+		Name:     "Actress",
+		Provider: "TEST",
+		Homepage: "https://example.com/actor/1",
+	}).Error)
+	require.NoError(t, db.Create(&model.MovieInfo{
+		ID:       "m1",
+		Number:   "TEST-001",
+		Title:    "Title",
+		Provider: "TEST",
+		Homepage: "https://example.com/m1",
+		CoverURL: "https://example.com/m1.jpg",
+		Actors:   []string{"Actress", `Actor "Quoted"`, "Actress"},
+	}).Error)
+	require.NoError(t, m.Up())
+
+	var links []*model.MovieActor
+	require.NoError(t, db.Order("position").Find(&links).Error)
+	assert.Equal(t, []*model.MovieActor{
+		{Provider: "TEST", MovieID: "m1", Name: "Actress", ActorID: "1", Position: 0},
+		{Provider: "TEST", MovieID: "m1", Name: `Actor "Quoted"`, ActorID: "", Position: 1},
+	}, links)
+}
@@ -0,0 +1,5 @@
+-- The collation and the pg_trgm extension are kept, since they may be
+-- used by other tables.
+DROP TABLE IF EXISTS movie_reviews;
+DROP TABLE IF EXISTS actor_metadata;
+DROP TABLE IF EXISTS movie_metadata;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS api_tokens (
+  token text,
+  name text,
+  scopes text[],
+  expires_at timestamptz,
+  rate_limit bigint,
+  burst bigint,
+  PRIMARY KEY (token)
+);
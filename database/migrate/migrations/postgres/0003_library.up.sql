@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS library_files (
+  path text,
+  size bigint,
+  mod_time timestamptz,
+  number text,
+  part bigint,
+  subtitle boolean,
+  status text,
+  provider text,
+  movie_id text,
+  confidence decimal,
+  candidates JSONB,
+  created_at timestamptz,
+  updated_at timestamptz,
+  PRIMARY KEY (path)
+);
+
+CREATE INDEX IF NOT EXISTS idx_library_files_status ON library_files (status);
+
+CREATE TABLE IF NOT EXISTS library_moves (
+  id bigserial,
+  batch bigint,
+  source text,
+  target text,
+  root text,
+  undone boolean,
+  created_at timestamptz,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_library_moves_batch ON library_moves (batch);
+
+CREATE TABLE IF NOT EXISTS library_failures (
+  path text,
+  stage text,
+  error text,
+  attempts bigint,
+  created_at timestamptz,
+  updated_at timestamptz,
+  PRIMARY KEY (path, stage)
+);
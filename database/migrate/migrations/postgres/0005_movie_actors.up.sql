@@ -0,0 +1,22 @@
+-- Movie to actor links, derived from the actors of movies.
+CREATE TABLE IF NOT EXISTS movie_actors (
+  provider text,
+  movie_id text,
+  name text,
+  actor_id text,
+  position bigint,
+  PRIMARY KEY (provider, movie_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_actors_name ON movie_actors (name);
+CREATE INDEX IF NOT EXISTS idx_movie_actors_actor_id ON movie_actors (provider, actor_id);
+
+-- Backfill the links of existing movies.
+INSERT INTO movie_actors (provider, movie_id, name, actor_id, position)
+SELECT m.provider, m.id, a.name,
+  COALESCE((SELECT am.id FROM actor_metadata am
+            WHERE am.provider = m.provider AND am.name = a.name LIMIT 1), ''),
+  a.position - 1
+FROM movie_metadata m, unnest(m.actors) WITH ORDINALITY AS a(name, position)
+WHERE a.name <> ''
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `movie_reviews`;
+DROP TABLE IF EXISTS `actor_metadata`;
+DROP TABLE IF EXISTS `movie_metadata`;
@@ -0,0 +1,60 @@
+-- Metadata tables, same as created by gorm AutoMigrate before, so that
+-- existing databases are adopted as is.
+CREATE TABLE IF NOT EXISTS `movie_metadata` (
+  `id` text,
+  `number` text,
+  `title` text,
+  `summary` text,
+  `provider` text,
+  `homepage` text,
+  `director` text,
+  `actors` text[],
+  `thumb_url` text,
+  `big_thumb_url` text,
+  `cover_url` text,
+  `big_cover_url` text,
+  `preview_video_url` text,
+  `preview_video_hls_url` text,
+  `preview_images` text[],
+  `maker` text,
+  `label` text,
+  `series` text,
+  `genres` text[],
+  `score` real,
+  `runtime` integer,
+  `release_date` date,
+  `created_at` datetime,
+  `updated_at` datetime,
+  PRIMARY KEY (`id`, `provider`)
+);
+
+CREATE TABLE IF NOT EXISTS `actor_metadata` (
+  `id` text,
+  `name` text,
+  `provider` text,
+  `homepage` text,
+  `summary` text,
+  `hobby` text,
+  `skill` text,
+  `blood_type` text,
+  `cup_size` text,
+  `measurements` text,
+  `nationality` text,
+  `height` integer,
+  `aliases` text[],
+  `images` text[],
+  `birthday` date,
+  `debut_date` date,
+  `created_at` datetime,
+  `updated_at` datetime,
+  PRIMARY KEY (`id`, `provider`)
+);
+
+CREATE TABLE IF NOT EXISTS `movie_reviews` (
+  `id` text,
+  `provider` text,
+  `reviews` JSON,
+  `created_at` datetime,
+  `updated_at` datetime,
+  PRIMARY KEY (`id`, `provider`)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `api_tokens` (
+  `token` text,
+  `name` text,
+  `scopes` text[],
+  `expires_at` datetime,
+  `rate_limit` integer,
+  `burst` integer,
+  PRIMARY KEY (`token`)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `library_failures`;
+DROP TABLE IF EXISTS `library_moves`;
+DROP TABLE IF EXISTS `library_files`;
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS `library_files` (
+  `path` text,
+  `size` integer,
+  `mod_time` datetime,
+  `number` text,
+  `part` integer,
+  `subtitle` numeric,
+  `status` text,
+  `provider` text,
+  `movie_id` text,
+  `confidence` real,
+  `candidates` JSON,
+  `created_at` datetime,
+  `updated_at` datetime,
+  PRIMARY KEY (`path`)
+);
+
+CREATE INDEX IF NOT EXISTS `idx_library_files_status` ON `library_files` (`status`);
+
+CREATE TABLE IF NOT EXISTS `library_moves` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `batch` integer,
+  `source` text,
+  `target` text,
+  `root` text,
+  `undone` numeric,
+  `created_at` datetime
+);
+
+CREATE INDEX IF NOT EXISTS `idx_library_moves_batch` ON `library_moves` (`batch`);
+
+CREATE TABLE IF NOT EXISTS `library_failures` (
+  `path` text,
+  `stage` text,
+  `error` text,
+  `attempts` integer,
+  `created_at` datetime,
+  `updated_at` datetime,
+  PRIMARY KEY (`path`, `stage`)
+);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS `actor_metadata_fts_update`;
+DROP TRIGGER IF EXISTS `actor_metadata_fts_delete`;
+DROP TRIGGER IF EXISTS `actor_metadata_fts_insert`;
+DROP TABLE IF EXISTS `actor_metadata_fts`;
+DROP TRIGGER IF EXISTS `movie_metadata_fts_update`;
+DROP TRIGGER IF EXISTS `movie_metadata_fts_delete`;
+DROP TRIGGER IF EXISTS `movie_metadata_fts_insert`;
+DROP TABLE IF EXISTS `movie_metadata_fts`;
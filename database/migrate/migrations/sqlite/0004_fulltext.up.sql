@@ -0,0 +1,65 @@
+-- Full-text indexes of the metadata tables. They are external content
+-- tables keyed by the rowid of the metadata rows, and kept in sync by
+-- the triggers below. The trigram tokenizer matches any substring of
+-- at least three characters, which also works for Japanese text.
+CREATE VIRTUAL TABLE IF NOT EXISTS `movie_metadata_fts` USING fts5(
+  `id`,
+  `number`,
+  `title`,
+  `summary`,
+  `actors`,
+  `genres`,
+  content = 'movie_metadata',
+  tokenize = 'trigram'
+);
+
+CREATE TRIGGER IF NOT EXISTS `movie_metadata_fts_insert` AFTER INSERT ON `movie_metadata`
+BEGIN
+  INSERT INTO `movie_metadata_fts` (`rowid`, `id`, `number`, `title`, `summary`, `actors`, `genres`)
+  VALUES (new.`rowid`, new.`id`, new.`number`, new.`title`, new.`summary`, new.`actors`, new.`genres`);
+END;
+
+CREATE TRIGGER IF NOT EXISTS `movie_metadata_fts_delete` AFTER DELETE ON `movie_metadata`
+BEGIN
+  INSERT INTO `movie_metadata_fts` (`movie_metadata_fts`, `rowid`, `id`, `number`, `title`, `summary`, `actors`, `genres`)
+  VALUES ('delete', old.`rowid`, old.`id`, old.`number`, old.`title`, old.`summary`, old.`actors`, old.`genres`);
+END;
+
+CREATE TRIGGER IF NOT EXISTS `movie_metadata_fts_update` AFTER UPDATE ON `movie_metadata`
+BEGIN
+  INSERT INTO `movie_metadata_fts` (`movie_metadata_fts`, `rowid`, `id`, `number`, `title`, `summary`, `actors`, `genres`)
+  VALUES ('delete', old.`rowid`, old.`id`, old.`number`, old.`title`, old.`summary`, old.`actors`, old.`genres`);
+  INSERT INTO `movie_metadata_fts` (`rowid`, `id`, `number`, `title`, `summary`, `actors`, `genres`)
+  VALUES (new.`rowid`, new.`id`, new.`number`, new.`title`, new.`summary`, new.`actors`, new.`genres`);
+END;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS `actor_metadata_fts` USING fts5(
+  `name`,
+  `aliases`,
+  content = 'actor_metadata',
+  tokenize = 'trigram'
+);
+
+CREATE TRIGGER IF NOT EXISTS `actor_metadata_fts_insert` AFTER INSERT ON `actor_metadata`
+BEGIN
+  INSERT INTO `actor_metadata_fts` (`rowid`, `name`, `aliases`)
+  VALUES (new.`rowid`, new.`name`, new.`aliases`);
+END;
+
+CREATE TRIGGER IF NOT EXISTS `actor_metadata_fts_delete` AFTER DELETE ON `actor_metadata`
+BEGIN
+  INSERT INTO `actor_metadata_fts` (`actor_metadata_fts`, `rowid`, `name`, `aliases`)
+  VALUES ('delete', old.`rowid`, old.`name`, old.`aliases`);
+END;
+
+CREATE TRIGGER IF NOT EXISTS `actor_metadata_fts_update` AFTER UPDATE ON `actor_metadata`
+BEGIN
+  INSERT INTO `actor_metadata_fts` (`actor_metadata_fts`, `rowid`, `name`, `aliases`)
+  VALUES ('delete', old.`rowid`, old.`name`, old.`aliases`);
+  INSERT INTO `actor_metadata_fts` (`rowid`, `name`, `aliases`)
+  VALUES (new.`rowid`, new.`name`, new.`aliases`);
+END;
+
+-- Index the existing rows.
+INSERT INTO `movie_metadata_fts` (`movie_metadata_fts`) VALUES ('rebuild');
+INSERT INTO `actor_metadata_fts` (`actor_metadata_fts`) VALUES ('rebuild');
@@ -0,0 +1,28 @@
+-- Movie to actor links, derived from the actors of movies.
+CREATE TABLE IF NOT EXISTS `movie_actors` (
+  `provider` text,
+  `movie_id` text,
+  `name` text,
+  `actor_id` text,
+  `position` integer,
+  PRIMARY KEY (`provider`, `movie_id`, `name`)
+);
+
+CREATE INDEX IF NOT EXISTS `idx_movie_actors_name` ON `movie_actors` (`name`);
+CREATE INDEX IF NOT EXISTS `idx_movie_actors_actor_id` ON `movie_actors` (`provider`, `actor_id`);
+
+-- Backfill the links of existing movies. The actors are stored as
+-- postgres array literals with quoted elements, e.g. {"a","b"}, which
+-- are read as JSON arrays.
+INSERT OR IGNORE INTO `movie_actors` (`provider`, `movie_id`, `name`, `actor_id`, `position`)
+SELECT m.`provider`, m.`id`, j.`value`,
+  COALESCE((SELECT a.`id` FROM `actor_metadata` a
+            WHERE a.`provider` = m.`provider` AND a.`name` = j.`value` LIMIT 1), ''),
+  j.`key`
+FROM `movie_metadata` m, json_each(
+  CASE WHEN json_valid('[' || substr(m.`actors`, 2, length(m.`actors`) - 2) || ']')
+    THEN '[' || substr(m.`actors`, 2, length(m.`actors`) - 2) || ']'
+    ELSE '[]'
+  END
+) j
+WHERE m.`actors` IS NOT NULL AND j.`value` <> '';
@@ -7,12 +7,12 @@ import (
 	"sync"
 
 	"golang.org/x/text/language"
-	"gorm.io/gorm/clause"
 
 	"github.com/metatube-community/metatube-sdk-go/collection/sets"
 	"github.com/metatube-community/metatube-sdk-go/collection/slices"
 	"github.com/metatube-community/metatube-sdk-go/common/comparer"
 	"github.com/metatube-community/metatube-sdk-go/common/parser"
+	"github.com/metatube-community/metatube-sdk-go/engine/dbengine"
 	"github.com/metatube-community/metatube-sdk-go/engine/providerid"
 	"github.com/metatube-community/metatube-sdk-go/model"
 	mt "github.com/metatube-community/metatube-sdk-go/provider"
@@ -153,6 +153,63 @@ func (e *Engine) getActorInfoFromDB(provider mt.ActorProvider, id string) (*mode
 	return info, err
 }
 
+// GetActorInfosByNamesFromDB returns the saved actor infos of the names,
+// the one with images from the provider of the highest priority is
+// returned if a name matches multiple actors.
+func (e *Engine) GetActorInfosByNamesFromDB(names ...string) ([]*model.ActorInfo, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	var infos []*model.ActorInfo
+	if err := e.db.Where("name IN ?", names).Find(&infos).Error; err != nil {
+		return nil, err
+	}
+	best := make(map[string]*model.ActorInfo)
+	for _, info := range infos {
+		provider, err := e.GetActorProviderByName(info.Provider)
+		if err != nil {
+			continue // provider not available.
+		}
+		prev, ok := best[info.Name]
+		if !ok || (len(prev.Images) == 0 && len(info.Images) > 0) ||
+			(len(info.Images) > 0 && provider.Priority() > e.MustGetActorProviderByName(prev.Provider).Priority()) {
+			best[info.Name] = info
+		}
+	}
+	results := make([]*model.ActorInfo, 0, len(best))
+	for _, name := range names {
+		if info, ok := best[name]; ok {
+			results = append(results, info)
+		}
+	}
+	return results, nil
+}
+
+// GetActorFilmographyFromDB returns the saved movies of the saved actor,
+// newest first. Movies of other providers are matched by the name and
+// aliases of the actor.
+func (e *Engine) GetActorFilmographyFromDB(pid providerid.ProviderID) ([]*model.MovieSearchResult, error) {
+	db := dbengine.New(e.db)
+	info, err := db.GetActorInfo(pid)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetFilmography(dbengine.FilmographyOptions{
+		Provider: info.Provider,
+		ActorID:  info.ID,
+		Names:    append([]string{info.Name}, info.Aliases...),
+	})
+}
+
+// GetFilmographyByNamesFromDB returns the saved movies of any of the
+// actor names, newest first.
+func (e *Engine) GetFilmographyByNamesFromDB(names ...string) ([]*model.MovieSearchResult, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return dbengine.New(e.db).GetFilmography(dbengine.FilmographyOptions{Names: names})
+}
+
 func (e *Engine) getActorInfoWithCallback(provider mt.ActorProvider, id string, lazy bool, callback func() (*model.ActorInfo, error)) (info *model.ActorInfo, err error) {
 	defer func() {
 		// metadata validation check.
@@ -181,10 +238,9 @@ func (e *Engine) getActorInfoWithCallback(provider mt.ActorProvider, id string,
 	// Delayed info auto-save.
 	defer func() {
 		if err == nil && info.IsValid() {
-			// Make sure we save the original info here.
-			e.db.Clauses(clause.OnConflict{
-				UpdateAll: true,
-			}).Create(info) // ignore error
+			// Make sure we save the original info here, and link it
+			// to the saved movies.
+			_ = dbengine.New(e.db).SaveActorInfo(info) // ignore error
 		}
 	}()
 	return callback()
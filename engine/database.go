@@ -4,25 +4,39 @@ import (
 	"fmt"
 
 	"github.com/metatube-community/metatube-sdk-go/database"
-	"github.com/metatube-community/metatube-sdk-go/model"
+	"github.com/metatube-community/metatube-sdk-go/database/migrate"
+	"github.com/metatube-community/metatube-sdk-go/engine/dbengine"
 )
 
+// DBAutoMigrate applies pending schema migrations if v is true, or
+// warns of them otherwise. It always refuses to run against a schema
+// migrated by a newer version.
 func (e *Engine) DBAutoMigrate(v bool) error {
-	if !v {
-		return nil
+	m, err := migrate.New(e.db)
+	if err != nil {
+		return err
 	}
-	// Create Case-Insensitive Collation for Postgres.
-	if e.DBDriver() == database.Postgres {
-		e.db.Exec(`CREATE COLLATION IF NOT EXISTS NOCASE (
-		provider = icu,
-		locale = 'und-u-ks-level2',
-		deterministic = FALSE)`)
+	if v {
+		if err = m.Up(); err != nil {
+			return err
+		}
 	}
-	return e.db.AutoMigrate(
-		&model.MovieInfo{},
-		&model.ActorInfo{},
-		&model.MovieReviewInfo{},
-	)
+	if err = m.Check(); err != nil {
+		return err
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			// data of pending migrations, e.g. the actor links of
+			// movies, are not saved until migrated.
+			e.logger.Printf("WARNING: pending DB migration %d_%s, enable -db-auto-migrate or run `metatube migrate up` to apply",
+				status.Version, status.Name)
+		}
+	}
+	return nil
 }
 
 func (e *Engine) DBDriver() string {
@@ -40,3 +54,9 @@ func (e *Engine) DBVersion() (version string, err error) {
 	}
 	return
 }
+
+// QueryMovies lists the cached movies matching the query, it never
+// fetches from providers.
+func (e *Engine) QueryMovies(q dbengine.MovieQuery) (*dbengine.MovieQueryResult, error) {
+	return dbengine.New(e.db).QueryMovies(q)
+}
@@ -3,6 +3,7 @@ package dbengine
 import (
 	"fmt"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
 	"github.com/metatube-community/metatube-sdk-go/database"
@@ -32,9 +33,19 @@ func (e *engine) SaveActorInfo(info *model.ActorInfo) error {
 	if !info.IsValid() {
 		return fmt.Errorf("invalid %T", info)
 	}
-	return e.DB().Clauses(clause.OnConflict{
+	if err := e.DB().Clauses(clause.OnConflict{
 		UpdateAll: true,
-	}).Create(info).Error
+	}).Create(info).Error; err != nil {
+		return err
+	}
+	// the actor links are saved apart, so that the info is kept even if
+	// the links cannot be saved, e.g. the migrations are pending.
+	if err := e.DB().Transaction(func(tx *gorm.DB) error {
+		return linkMovieActors(tx, info)
+	}); err != nil {
+		return fmt.Errorf("save actor links: %w", err)
+	}
+	return nil
 }
 
 func (e *engine) SearchActor(keyword string, opts ActorSearchOptions) ([]*model.ActorSearchResult, error) {
@@ -54,7 +65,21 @@ func (e *engine) SearchActor(keyword string, opts ActorSearchOptions) ([]*model.
 			`(name COLLATE NOCASE = ? OR similarity(name, ?) > ?)`,
 			keyword, keyword, opts.Threshold,
 		)
-	} else { // Sqlite
+	} else if query, ok := fulltextQuery(keyword); ok { // Sqlite
+		// rank by bm25, where the matches of name weigh more than aliases.
+		tx = tx.Select(model.ActorMetadataTableName+`.*`).
+			Joins(fmt.Sprintf(
+				`JOIN (
+				  SELECT rowid, bm25(%[1]s, 10.0, 5.0) AS rank
+				  FROM %[1]s WHERE %[1]s MATCH ?
+				) AS fts ON fts.rowid = %[2]s.rowid`,
+				actorFulltextTableName, model.ActorMetadataTableName,
+			), query).
+			Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:  `name COLLATE NOCASE = ? DESC, fts.rank`,
+				Vars: []any{keyword},
+			}})
+	} else { // Sqlite, keyword too short for the trigram index.
 		pattern := "%" + keyword + "%"
 		tx = tx.Where(
 			`(name COLLATE NOCASE = ? OR name LIKE ? COLLATE NOCASE)`,
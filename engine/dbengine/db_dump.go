@@ -0,0 +1,344 @@
+package dbengine
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+type dumpEngine interface {
+	Export(io.Writer, ExportOptions) (*DumpStats, error)
+	Import(io.Reader, ImportOptions) (*DumpStats, error)
+}
+
+var _ dumpEngine = (*engine)(nil)
+
+// Dump record kinds.
+const (
+	DumpMovie  = "movie"
+	DumpActor  = "actor"
+	DumpReview = "review"
+)
+
+// DumpKinds are all the dump record kinds, in the export order.
+var DumpKinds = []string{DumpMovie, DumpActor, DumpReview}
+
+// ConflictPolicy decides how to import records that already exist.
+type ConflictPolicy string
+
+const (
+	// ConflictNewer overwrites existing records only if the imported
+	// ones are updated later, which is the default.
+	ConflictNewer ConflictPolicy = "newer"
+	// ConflictOverwrite always overwrites existing records.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictSkip always keeps existing records.
+	ConflictSkip ConflictPolicy = "skip"
+)
+
+// ParseConflictPolicy parses the policy name, empty means ConflictNewer.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(s); policy {
+	case "":
+		return ConflictNewer, nil
+	case ConflictNewer, ConflictOverwrite, ConflictSkip:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid conflict policy: %s", s)
+}
+
+// dumpBatchSize is the number of records written at once.
+const dumpBatchSize = 100
+
+// DumpRecord is a line of the JSONL dump. Timestamps are kept outside
+// the data, since they are omitted in the JSON of the models.
+type DumpRecord struct {
+	Kind      string          `json:"kind"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// DumpStats is the number of records exported or imported.
+type DumpStats struct {
+	Movies  int `json:"movies"`
+	Actors  int `json:"actors"`
+	Reviews int `json:"reviews"`
+	// Skipped is the number of records kept by the conflict policy.
+	Skipped int `json:"skipped"`
+}
+
+func (s *DumpStats) add(kind string, n int) {
+	switch kind {
+	case DumpMovie:
+		s.Movies += n
+	case DumpActor:
+		s.Actors += n
+	case DumpReview:
+		s.Reviews += n
+	}
+}
+
+type ExportOptions struct {
+	// Kinds are the record kinds to export, all kinds if empty.
+	Kinds []string
+	// Provider exports the records of this provider only.
+	Provider string
+	// UpdatedSince exports the records updated since then only.
+	UpdatedSince time.Time
+	// Gzip compresses the dump.
+	Gzip bool
+}
+
+type ImportOptions struct {
+	Conflict ConflictPolicy
+}
+
+// Export streams the records as JSONL, one record per line.
+func (e *engine) Export(w io.Writer, opts ExportOptions) (*DumpStats, error) {
+	kinds := opts.Kinds
+	if len(kinds) == 0 {
+		kinds = DumpKinds
+	}
+	for _, kind := range kinds {
+		if !slices.Contains(DumpKinds, kind) {
+			return nil, fmt.Errorf("invalid dump kind: %s", kind)
+		}
+	}
+
+	if opts.Gzip {
+		gw := gzip.NewWriter(w)
+		defer gw.Close()
+		w = gw
+	}
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	stats := &DumpStats{}
+	for _, kind := range DumpKinds {
+		if !slices.Contains(kinds, kind) {
+			continue
+		}
+		var err error
+		switch kind {
+		case DumpMovie:
+			err = exportTable[model.MovieInfo](e.exportTx(opts), enc, kind, stats,
+				func(v *model.MovieInfo) model.TimeTracker { return v.TimeTracker })
+		case DumpActor:
+			err = exportTable[model.ActorInfo](e.exportTx(opts), enc, kind, stats,
+				func(v *model.ActorInfo) model.TimeTracker { return v.TimeTracker })
+		case DumpReview:
+			err = exportTable[model.MovieReviewInfo](e.exportTx(opts), enc, kind, stats,
+				func(v *model.MovieReviewInfo) model.TimeTracker { return v.TimeTracker })
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (e *engine) exportTx(opts ExportOptions) *gorm.DB {
+	tx := e.DB()
+	if opts.Provider != "" {
+		tx = tx.Where(`provider COLLATE NOCASE = ?`, opts.Provider)
+	}
+	if !opts.UpdatedSince.IsZero() {
+		tx = tx.Where(`updated_at >= ?`, opts.UpdatedSince)
+	}
+	return tx
+}
+
+// exportTable streams the rows in order of the primary key, FindInBatches
+// is not used since it pages by the last id only, which skips the rows
+// of composite keys.
+func exportTable[T any](tx *gorm.DB, enc *json.Encoder, kind string, stats *DumpStats, timeOf func(*T) model.TimeTracker) error {
+	tx = tx.Model(new(T)).Order("provider").Order("id")
+	rows, err := tx.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		v := new(T)
+		if err = tx.ScanRows(rows, v); err != nil {
+			return err
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		tt := timeOf(v)
+		if err = enc.Encode(&DumpRecord{
+			Kind:      kind,
+			CreatedAt: tt.CreatedAt,
+			UpdatedAt: tt.UpdatedAt,
+			Data:      data,
+		}); err != nil {
+			return err
+		}
+		stats.add(kind, 1)
+	}
+	return rows.Err()
+}
+
+// gzipMagic is the header of gzip streams.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Import upserts the records of the JSONL dump, gzip-compressed dumps
+// are detected automatically. Existing records are resolved with the
+// conflict policy.
+func (e *engine) Import(r io.Reader, opts ImportOptions) (*DumpStats, error) {
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictNewer
+	}
+	if _, err := ParseConflictPolicy(string(opts.Conflict)); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	if header, _ := br.Peek(len(gzipMagic)); slices.Equal(header, gzipMagic) {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		br = bufio.NewReader(gr)
+	}
+
+	var (
+		stats   = &DumpStats{}
+		movies  []*model.MovieInfo
+		actors  []*model.ActorInfo
+		reviews []*model.MovieReviewInfo
+	)
+	flush := func() error {
+		imported := slices.Clone(movies)
+		if err := importBatch(e.DB(), &movies, DumpMovie, opts.Conflict, stats); err != nil {
+			return err
+		}
+		if err := e.DB().Transaction(func(tx *gorm.DB) error {
+			return syncMovieActors(tx, imported)
+		}); err != nil {
+			return err
+		}
+		if err := importBatch(e.DB(), &actors, DumpActor, opts.Conflict, stats); err != nil {
+			return err
+		}
+		return importBatch(e.DB(), &reviews, DumpReview, opts.Conflict, stats)
+	}
+
+	dec := json.NewDecoder(br)
+	for line := 1; ; line++ {
+		record := &DumpRecord{}
+		if err := dec.Decode(record); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+		tt := model.TimeTracker{CreatedAt: record.CreatedAt, UpdatedAt: record.UpdatedAt}
+		var err error
+		switch record.Kind {
+		case DumpMovie:
+			err = decodeRecord(record, &movies, func(v *model.MovieInfo) bool {
+				v.TimeTracker = tt
+				return v.IsValid()
+			})
+		case DumpActor:
+			err = decodeRecord(record, &actors, func(v *model.ActorInfo) bool {
+				v.TimeTracker = tt
+				return v.IsValid()
+			})
+		case DumpReview:
+			err = decodeRecord(record, &reviews, func(v *model.MovieReviewInfo) bool {
+				v.TimeTracker = tt
+				return v.IsValid()
+			})
+		default:
+			err = fmt.Errorf("invalid dump kind: %s", record.Kind)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+		if len(movies)+len(actors)+len(reviews) >= dumpBatchSize {
+			if err = flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func decodeRecord[T any](record *DumpRecord, batch *[]*T, validate func(*T) bool) error {
+	v := new(T)
+	if err := json.Unmarshal(record.Data, v); err != nil {
+		return err
+	}
+	if !validate(v) {
+		return fmt.Errorf("invalid %T", v)
+	}
+	*batch = append(*batch, v)
+	return nil
+}
+
+func importBatch[T any](tx *gorm.DB, batch *[]*T, kind string, policy ConflictPolicy, stats *DumpStats) error {
+	if len(*batch) == 0 {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(new(T)); err != nil {
+		return err
+	}
+	// update all columns with the imported ones, including updated_at,
+	// which UpdateAll would set to the current time.
+	var primaryKeys []clause.Column
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		switch {
+		case field.DBName == "":
+		case field.PrimaryKey:
+			primaryKeys = append(primaryKeys, clause.Column{Name: field.DBName})
+		case field.AutoCreateTime == 0:
+			columns = append(columns, field.DBName)
+		}
+	}
+	onConflict := clause.OnConflict{
+		Columns:   primaryKeys,
+		DoUpdates: clause.AssignmentColumns(columns),
+	}
+	switch policy {
+	case ConflictSkip:
+		onConflict = clause.OnConflict{DoNothing: true}
+	case ConflictNewer:
+		onConflict.Where = clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: fmt.Sprintf("excluded.updated_at > %s.updated_at", stmt.Quote(stmt.Table))},
+		}}
+	}
+	result := tx.Clauses(onConflict).Create(*batch)
+	if result.Error != nil {
+		return result.Error
+	}
+	n := int(result.RowsAffected)
+	stats.add(kind, n)
+	stats.Skipped += len(*batch) - n
+	*batch = (*batch)[:0]
+	return nil
+}
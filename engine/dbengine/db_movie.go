@@ -3,7 +3,10 @@ package dbengine
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
 	"github.com/metatube-community/metatube-sdk-go/database"
@@ -21,6 +24,25 @@ type movieEngine interface {
 
 var _ movieEngine = (*engine)(nil)
 
+// Full-text index tables of sqlite, see the 0004_fulltext migration.
+const (
+	movieFulltextTableName = "movie_metadata_fts"
+	actorFulltextTableName = "actor_metadata_fts"
+)
+
+// minFulltextLength is the min keyword length of the trigram tokenizer.
+const minFulltextLength = 3
+
+// fulltextQuery quotes the keyword as a FTS5 phrase, which matches the
+// keyword as a substring with the trigram tokenizer. It returns false
+// if the keyword is too short to match.
+func fulltextQuery(keyword string) (string, bool) {
+	if utf8.RuneCountInString(keyword) < minFulltextLength {
+		return "", false
+	}
+	return `"` + strings.ReplaceAll(keyword, `"`, `""`) + `"`, true
+}
+
 func (e *engine) GetMovieInfo(pid providerid.ProviderID) (*model.MovieInfo, error) {
 	info := &model.MovieInfo{}
 	err := e.DB().
@@ -35,9 +57,19 @@ func (e *engine) SaveMovieInfo(info *model.MovieInfo) error {
 	if !info.IsValid() {
 		return fmt.Errorf("invalid %T", info)
 	}
-	return e.DB().Clauses(clause.OnConflict{
+	if err := e.DB().Clauses(clause.OnConflict{
 		UpdateAll: true,
-	}).Create(info).Error
+	}).Create(info).Error; err != nil {
+		return err
+	}
+	// the actor links are saved apart, so that the info is kept even if
+	// the links cannot be saved, e.g. the migrations are pending.
+	if err := e.DB().Transaction(func(tx *gorm.DB) error {
+		return saveMovieActors(tx, info)
+	}); err != nil {
+		return fmt.Errorf("save actor links: %w", err)
+	}
+	return nil
 }
 
 func (e *engine) SearchMovie(keyword string, opts MovieSearchOptions) ([]*model.MovieSearchResult, error) {
@@ -69,7 +101,21 @@ func (e *engine) SearchMovie(keyword string, opts MovieSearchOptions) ([]*model.
 			keyword, opts.Thresholds.Number,
 			keyword, opts.Thresholds.Title,
 		)
-	} else { // sqlite
+	} else if query, ok := fulltextQuery(keyword); ok { // sqlite
+		// rank by bm25, where the matches of number and id weigh most.
+		tx = tx.Select(model.MovieMetadataTableName+`.*`).
+			Joins(fmt.Sprintf(
+				`JOIN (
+				  SELECT rowid, bm25(%[1]s, 10.0, 10.0, 5.0, 1.0, 3.0, 1.0) AS rank
+				  FROM %[1]s WHERE %[1]s MATCH ?
+				) AS fts ON fts.rowid = %[2]s.rowid`,
+				movieFulltextTableName, model.MovieMetadataTableName,
+			), query).
+			Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:  `number COLLATE NOCASE = ? DESC, fts.rank`,
+				Vars: []any{keyword},
+			}})
+	} else { // sqlite, keyword too short for the trigram index.
 		tx = tx.Where(
 			`(
 			  number COLLATE NOCASE = ?
@@ -0,0 +1,138 @@
+package dbengine
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+type filmographyEngine interface {
+	GetFilmography(FilmographyOptions) ([]*model.MovieSearchResult, error)
+}
+
+var _ filmographyEngine = (*engine)(nil)
+
+type FilmographyOptions struct {
+	// Provider and ActorID match the movies linked to the actor of the
+	// same provider.
+	Provider string
+	ActorID  string
+	// Names match the movies of any provider by actor names.
+	Names  []string
+	Limit  int
+	Offset int
+}
+
+// GetFilmography lists the cached movies of the actor, sorted by the
+// release date, newest first.
+func (e *engine) GetFilmography(opts FilmographyOptions) ([]*model.MovieSearchResult, error) {
+	links := e.DB().Model(&model.MovieActor{}).Select("provider", "movie_id")
+	switch {
+	case opts.ActorID != "" && len(opts.Names) > 0:
+		links = links.Where(`(provider = ? AND actor_id = ?) OR name IN ?`,
+			opts.Provider, opts.ActorID, opts.Names)
+	case opts.ActorID != "":
+		links = links.Where(`provider = ? AND actor_id = ?`, opts.Provider, opts.ActorID)
+	case len(opts.Names) > 0:
+		links = links.Where(`name IN ?`, opts.Names)
+	default:
+		return nil, nil
+	}
+
+	tx := e.DB().Where(`(provider, id) IN (?)`, links).
+		Order("release_date DESC").Order("number")
+	if opts.Limit > 0 {
+		tx = tx.Limit(opts.Limit)
+	}
+	if opts.Offset > 0 {
+		tx = tx.Offset(opts.Offset)
+	}
+	var infos []*model.MovieInfo
+	if err := tx.Find(&infos).Error; err != nil {
+		return nil, err
+	}
+	results := make([]*model.MovieSearchResult, 0, len(infos))
+	for _, info := range infos {
+		if !info.IsValid() {
+			continue // ignore invalid info.
+		}
+		results = append(results, info.ToSearchResult())
+	}
+	return results, nil
+}
+
+// saveMovieActors replaces the actor links of the movie, the actor IDs
+// are resolved from the saved actors of the same provider.
+func saveMovieActors(tx *gorm.DB, info *model.MovieInfo) error {
+	if err := tx.Where(`provider = ? AND movie_id = ?`, info.Provider, info.ID).
+		Delete(&model.MovieActor{}).Error; err != nil {
+		return err
+	}
+	var (
+		links []*model.MovieActor
+		names []string
+		seen  = make(map[string]bool)
+	)
+	for i, name := range info.Actors {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		links = append(links, &model.MovieActor{
+			Provider: info.Provider,
+			MovieID:  info.ID,
+			Name:     name,
+			Position: i,
+		})
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	var actors []*model.ActorInfo
+	if err := tx.Select("id", "name").
+		Where(`provider = ? AND name IN ?`, info.Provider, names).
+		Find(&actors).Error; err != nil {
+		return err
+	}
+	actorIDs := make(map[string]string, len(actors))
+	for _, actor := range actors {
+		actorIDs[actor.Name] = actor.ID
+	}
+	for _, link := range links {
+		link.ActorID = actorIDs[link.Name]
+	}
+	return tx.Create(links).Error
+}
+
+// linkMovieActors links the saved actor to the movies of the same
+// provider, which are saved before the actor.
+func linkMovieActors(tx *gorm.DB, info *model.ActorInfo) error {
+	return tx.Model(&model.MovieActor{}).
+		Where(`provider = ? AND name = ? AND actor_id = ?`, info.Provider, info.Name, "").
+		Update("actor_id", info.ID).Error
+}
+
+// syncMovieActors replaces the actor links of the movies as saved in
+// the DB, which may differ from the given ones, e.g., skipped imports.
+func syncMovieActors(tx *gorm.DB, infos []*model.MovieInfo) error {
+	if len(infos) == 0 {
+		return nil
+	}
+	keys := make([][]any, 0, len(infos))
+	for _, info := range infos {
+		keys = append(keys, []any{info.Provider, info.ID})
+	}
+	var saved []*model.MovieInfo
+	if err := tx.Select("provider", "id", "actors").
+		Where(`(provider, id) IN ?`, keys).
+		Find(&saved).Error; err != nil {
+		return err
+	}
+	for _, info := range saved {
+		if err := saveMovieActors(tx, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+package dbengine
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+
+	"github.com/metatube-community/metatube-sdk-go/database"
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+type queryEngine interface {
+	QueryMovies(MovieQuery) (*MovieQueryResult, error)
+}
+
+var _ queryEngine = (*engine)(nil)
+
+// ErrInvalidQuery is returned if the query has invalid sort, cursor or
+// facets.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Sort keys of movie queries, prefixed by "-" for descending order.
+const (
+	SortReleaseDate = "release_date"
+	SortScore       = "score"
+	SortRuntime     = "runtime"
+	SortNumber      = "number"
+	SortUpdatedAt   = "updated_at"
+)
+
+// Facet fields of movie queries.
+const (
+	FacetProvider = "provider"
+	FacetActor    = "actor"
+	FacetMaker    = "maker"
+	FacetLabel    = "label"
+	FacetSeries   = "series"
+	FacetGenre    = "genre"
+)
+
+var (
+	sortKeys    = []string{SortReleaseDate, SortScore, SortRuntime, SortNumber, SortUpdatedAt}
+	facetFields = []string{FacetProvider, FacetActor, FacetMaker, FacetLabel, FacetSeries, FacetGenre}
+)
+
+const (
+	defaultQueryLimit = 20
+	maxQueryLimit     = 100
+	// facetLimit is the max number of values per facet.
+	facetLimit = 20
+)
+
+// MovieQuery filters the cached movies, zero fields are not filtered.
+type MovieQuery struct {
+	Provider string
+	Actor    string
+	Maker    string
+	Label    string
+	Series   string
+	Genre    string
+
+	ReleasedAfter  time.Time
+	ReleasedBefore time.Time
+	MinScore       float64
+	MaxScore       float64
+	MinRuntime     int
+	MaxRuntime     int
+
+	// Sort is one of the sort keys, "-release_date" by default.
+	Sort string
+	// Cursor is the NextCursor of the previous page.
+	Cursor string
+	Limit  int
+	// Facets are the fields to count values of.
+	Facets []string
+}
+
+func (q *MovieQuery) applyDefaults() {
+	if q.Sort == "" {
+		q.Sort = "-" + SortReleaseDate
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultQueryLimit
+	}
+	if q.Limit > maxQueryLimit {
+		q.Limit = maxQueryLimit
+	}
+}
+
+// FacetCount is the number of movies of a facet value.
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type MovieQueryResult struct {
+	Movies []*model.MovieSearchResult `json:"movies"`
+	// Total is the number of movies matching the filters.
+	Total int64 `json:"total"`
+	// NextCursor is empty on the last page.
+	NextCursor string                   `json:"next_cursor,omitempty"`
+	Facets     map[string][]*FacetCount `json:"facets,omitempty"`
+}
+
+// queryCursor is the position after the last movie of a page, which is
+// the sort value and the primary keys as the tiebreaker.
+type queryCursor struct {
+	Time     time.Time `json:"t,omitzero"`
+	Number   float64   `json:"n,omitempty"`
+	String   string    `json:"s,omitempty"`
+	Provider string    `json:"p"`
+	ID       string    `json:"i"`
+}
+
+func (c *queryCursor) value(key string) any {
+	switch key {
+	case SortReleaseDate, SortUpdatedAt:
+		return c.Time
+	case SortScore, SortRuntime:
+		return c.Number
+	default:
+		return c.String
+	}
+}
+
+func newQueryCursor(key string, info *model.MovieInfo) *queryCursor {
+	c := &queryCursor{Provider: info.Provider, ID: info.ID}
+	switch key {
+	case SortReleaseDate:
+		c.Time = time.Time(info.ReleaseDate)
+	case SortUpdatedAt:
+		c.Time = info.UpdatedAt
+	case SortScore:
+		c.Number = info.Score
+	case SortRuntime:
+		c.Number = float64(info.Runtime)
+	case SortNumber:
+		c.String = info.Number
+	}
+	return c
+}
+
+func (c *queryCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeQueryCursor(s string) (*queryCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor", ErrInvalidQuery)
+	}
+	c := &queryCursor{}
+	if err = json.Unmarshal(data, c); err != nil || c.Provider == "" || c.ID == "" {
+		return nil, fmt.Errorf("%w: cursor", ErrInvalidQuery)
+	}
+	return c, nil
+}
+
+// QueryMovies lists the cached movies matching the query, the results
+// are paginated by cursor. Facets are counted over all matches.
+func (e *engine) QueryMovies(q MovieQuery) (*MovieQueryResult, error) {
+	q.applyDefaults()
+
+	key, desc := strings.TrimPrefix(q.Sort, "-"), strings.HasPrefix(q.Sort, "-")
+	if !slices.Contains(sortKeys, key) {
+		return nil, fmt.Errorf("%w: sort %s", ErrInvalidQuery, q.Sort)
+	}
+	for _, facet := range q.Facets {
+		if !slices.Contains(facetFields, facet) {
+			return nil, fmt.Errorf("%w: facet %s", ErrInvalidQuery, facet)
+		}
+	}
+
+	result := &MovieQueryResult{}
+	if err := e.filterMovies(q).Model(&model.MovieInfo{}).Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+
+	// page.
+	tx := e.filterMovies(q)
+	if q.Cursor != "" {
+		c, err := decodeQueryCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		v := c.value(key)
+		tx = tx.Where(
+			fmt.Sprintf(`(%[1]s %[2]s ? OR (%[1]s = ? AND (provider, id) > (?, ?)))`, key, op),
+			v, v, c.Provider, c.ID,
+		)
+	}
+	order := key
+	if desc {
+		order += " DESC"
+	}
+	var infos []*model.MovieInfo
+	if err := tx.Order(order).Order("provider").Order("id").
+		Limit(q.Limit + 1 /* peek the next page */).Find(&infos).Error; err != nil {
+		return nil, err
+	}
+	if len(infos) > q.Limit {
+		infos = infos[:q.Limit]
+		result.NextCursor = newQueryCursor(key, infos[len(infos)-1]).encode()
+	}
+	result.Movies = make([]*model.MovieSearchResult, 0, len(infos))
+	for _, info := range infos {
+		result.Movies = append(result.Movies, info.ToSearchResult())
+	}
+
+	// facets.
+	if len(q.Facets) > 0 {
+		result.Facets = make(map[string][]*FacetCount, len(q.Facets))
+	}
+	for _, facet := range q.Facets {
+		var (
+			counts []*FacetCount
+			err    error
+		)
+		switch facet {
+		case FacetActor:
+			counts, err = e.countArrayFacet(q, "actors")
+		case FacetGenre:
+			counts, err = e.countArrayFacet(q, "genres")
+		default:
+			counts, err = e.countFacet(q, facet)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Facets[facet] = counts
+	}
+	return result, nil
+}
+
+// filterMovies returns a session with the filters of the query.
+func (e *engine) filterMovies(q MovieQuery) *gorm.DB {
+	tx := e.DB()
+	for _, filter := range [][2]string{
+		{"provider", q.Provider},
+		{"maker", q.Maker},
+		{"label", q.Label},
+		{"series", q.Series},
+	} {
+		if filter[1] != "" {
+			tx = tx.Where(filter[0]+` COLLATE NOCASE = ?`, filter[1])
+		}
+	}
+	if q.Actor != "" {
+		tx = e.whereArrayContains(tx, "actors", q.Actor)
+	}
+	if q.Genre != "" {
+		tx = e.whereArrayContains(tx, "genres", q.Genre)
+	}
+	if !q.ReleasedAfter.IsZero() {
+		tx = tx.Where(`release_date >= ?`, q.ReleasedAfter)
+	}
+	if !q.ReleasedBefore.IsZero() {
+		tx = tx.Where(`release_date <= ?`, q.ReleasedBefore)
+	}
+	if q.MinScore > 0 {
+		tx = tx.Where(`score >= ?`, q.MinScore)
+	}
+	if q.MaxScore > 0 {
+		tx = tx.Where(`score <= ?`, q.MaxScore)
+	}
+	if q.MinRuntime > 0 {
+		tx = tx.Where(`runtime >= ?`, q.MinRuntime)
+	}
+	if q.MaxRuntime > 0 {
+		tx = tx.Where(`runtime <= ?`, q.MaxRuntime)
+	}
+	return tx
+}
+
+func (e *engine) whereArrayContains(tx *gorm.DB, column, value string) *gorm.DB {
+	if e.Driver() == database.Postgres {
+		return tx.Where(`? = ANY(`+column+`)`, value)
+	}
+	// sqlite stores arrays as postgres array literals, whose elements
+	// are always quoted, e.g. {"a","b"}.
+	return tx.Where(`instr(`+column+`, ?) > 0`, quoteArrayElement(value))
+}
+
+// quoteArrayElement quotes the element the same as pq.StringArray.
+func quoteArrayElement(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (e *engine) countFacet(q MovieQuery, column string) ([]*FacetCount, error) {
+	var counts []*FacetCount
+	err := e.filterMovies(q).Model(&model.MovieInfo{}).
+		Select(column + ` AS value, count(*) AS count`).
+		Where(column + ` <> ''`).
+		Group(column).Order(`count DESC`).Order(`value`).
+		Limit(facetLimit).Scan(&counts).Error
+	return counts, err
+}
+
+func (e *engine) countArrayFacet(q MovieQuery, column string) ([]*FacetCount, error) {
+	var counts []*FacetCount
+	if e.Driver() == database.Postgres {
+		err := e.filterMovies(q).
+			Table(model.MovieMetadataTableName + `, unnest(` + column + `) AS value`).
+			Select(`value, count(*) AS count`).
+			Where(`value <> ''`).
+			Group(`value`).Order(`count DESC`).Order(`value`).
+			Limit(facetLimit).Scan(&counts).Error
+		return counts, err
+	}
+
+	// sqlite cannot unnest arrays, count them while scanning.
+	rows, err := e.filterMovies(q).Model(&model.MovieInfo{}).Select(column).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	countOf := make(map[string]int64)
+	for rows.Next() {
+		var values pq.StringArray
+		if err = rows.Scan(&values); err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			if value != "" {
+				countOf[value]++
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	for value, count := range countOf {
+		counts = append(counts, &FacetCount{Value: value, Count: count})
+	}
+	slices.SortFunc(counts, func(a, b *FacetCount) int {
+		if a.Count != b.Count {
+			return int(b.Count - a.Count)
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	return counts[:min(len(counts), facetLimit)], nil
+}
@@ -1,9 +1,12 @@
 package dbengine
 
 import (
+	"bytes"
 	"database/sql"
 	_ "embed"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/url"
@@ -11,6 +14,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/ory/dockertest"
@@ -327,6 +331,423 @@ func (s *DBEngineTestSuite) TestMovie_Reviews() {
 	})
 }
 
+func (s *DBEngineTestSuite) TestFulltext() {
+	if s.typ != database.Sqlite {
+		s.T().SkipNow()
+	}
+	const provider = "FTS"
+	saveMovie := func(t *testing.T, id, number, title string, actors ...string) {
+		require.NoError(t, s.eng.SaveMovieInfo(&model.MovieInfo{
+			ID:       id,
+			Number:   number,
+			Title:    title,
+			Provider: provider,
+			Homepage: "https://example.com/" + id,
+			CoverURL: "https://example.com/" + id + ".jpg",
+			Actors:   actors,
+		}))
+	}
+	saveMovie(s.T(), "fts001", "FTS-001", "真夏の果実と全文検索", "全文花子")
+	saveMovie(s.T(), "fts002", "FTS-002", "全文検索 FTS-001 の続編")
+	s.Require().NoError(s.eng.SaveActorInfo(&model.ActorInfo{
+		ID:       "fts",
+		Name:     "全文花子",
+		Provider: provider,
+		Homepage: "https://example.com/actor/fts",
+		Aliases:  []string{"索引太郎"},
+	}))
+
+	search := func(t *testing.T, keyword string) []string {
+		movies, err := s.eng.SearchMovie(keyword, MovieSearchOptions{Provider: provider})
+		require.NoError(t, err)
+		ids := make([]string, 0, len(movies))
+		for _, movie := range movies {
+			ids = append(ids, movie.ID)
+		}
+		return ids
+	}
+
+	s.T().Run("search movie by number (ranked)", func(t *testing.T) {
+		assert.Equal(t, []string{"fts001", "fts002"}, search(t, "fts-001"))
+	})
+
+	s.T().Run("search movie by title", func(t *testing.T) {
+		assert.Equal(t, []string{"fts001"}, search(t, "真夏の果実"))
+	})
+
+	s.T().Run("search movie by actor", func(t *testing.T) {
+		assert.Equal(t, []string{"fts001"}, search(t, "全文花子"))
+	})
+
+	s.T().Run("search movie after update", func(t *testing.T) {
+		saveMovie(t, "fts001", "FTS-001", "真冬の果実と全文検索")
+		assert.Empty(t, search(t, "真夏の果実"))
+		assert.Equal(t, []string{"fts001"}, search(t, "真冬の果実"))
+	})
+
+	s.T().Run("search actor by alias", func(t *testing.T) {
+		actors, err := s.eng.SearchActor("索引太郎", ActorSearchOptions{Provider: provider})
+		require.NoError(t, err)
+		require.Len(t, actors, 1)
+		assert.Equal(t, "fts", actors[0].ID)
+	})
+}
+
+func (s *DBEngineTestSuite) TestQueryMovies() {
+	const provider = "QUERY"
+	for i, data := range []struct {
+		maker  string
+		genres []string
+		actors []string
+		score  float64
+		date   string
+	}{
+		{"Maker A", []string{"Drama"}, []string{"Actor X", "Actor Y"}, 4.5, "2021-03-01"},
+		{"Maker A", []string{"Drama", "Comedy"}, []string{"Actor X"}, 3.0, "2022-06-15"},
+		{"Maker B", []string{"Comedy"}, []string{"Actor Y"}, 4.0, "2023-01-20"},
+		{"Maker B", []string{"Drama"}, []string{`Actor "Z"`}, 2.5, "2020-11-30"},
+		{"Maker C", nil, []string{"Actor X"}, 5.0, "2022-06-15"},
+	} {
+		id := fmt.Sprintf("q%d", i+1)
+		s.Require().NoError(s.eng.SaveMovieInfo(&model.MovieInfo{
+			ID:          id,
+			Number:      fmt.Sprintf("QUERY-%03d", i+1),
+			Title:       "Query " + id,
+			Provider:    provider,
+			Homepage:    "https://example.com/" + id,
+			CoverURL:    "https://example.com/" + id + ".jpg",
+			Maker:       data.maker,
+			Genres:      data.genres,
+			Actors:      data.actors,
+			Score:       data.score,
+			Runtime:     100 + i*10,
+			ReleaseDate: parser.ParseDate(data.date),
+		}))
+	}
+
+	query := func(t *testing.T, q MovieQuery) (*MovieQueryResult, []string) {
+		q.Provider = provider
+		result, err := s.eng.QueryMovies(q)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(result.Movies))
+		for _, movie := range result.Movies {
+			ids = append(ids, movie.ID)
+		}
+		return result, ids
+	}
+
+	s.T().Run("sort by release date", func(t *testing.T) {
+		result, ids := query(t, MovieQuery{})
+		assert.Equal(t, []string{"q3", "q2", "q5", "q1", "q4"}, ids)
+		assert.EqualValues(t, 5, result.Total)
+		assert.Empty(t, result.NextCursor)
+	})
+
+	s.T().Run("filters", func(t *testing.T) {
+		_, ids := query(t, MovieQuery{Maker: "maker a", Sort: SortScore})
+		assert.Equal(t, []string{"q2", "q1"}, ids)
+		_, ids = query(t, MovieQuery{Actor: "Actor X", Genre: "Drama", Sort: SortNumber})
+		assert.Equal(t, []string{"q1", "q2"}, ids)
+		_, ids = query(t, MovieQuery{Actor: `Actor "Z"`})
+		assert.Equal(t, []string{"q4"}, ids)
+		_, ids = query(t, MovieQuery{
+			ReleasedAfter:  parser.ParseTime("2021-01-01"),
+			ReleasedBefore: parser.ParseTime("2022-12-31"),
+			MinScore:       4,
+			Sort:           SortNumber,
+		})
+		assert.Equal(t, []string{"q1", "q5"}, ids)
+		_, ids = query(t, MovieQuery{MinRuntime: 110, MaxRuntime: 130, Sort: "-" + SortRuntime})
+		assert.Equal(t, []string{"q4", "q3", "q2"}, ids)
+	})
+
+	s.T().Run("cursor pagination", func(t *testing.T) {
+		var (
+			ids    []string
+			cursor string
+		)
+		for range 3 {
+			result, page := query(t, MovieQuery{Limit: 2, Cursor: cursor})
+			assert.LessOrEqual(t, len(page), 2)
+			ids = append(ids, page...)
+			if cursor = result.NextCursor; cursor == "" {
+				break
+			}
+		}
+		assert.Empty(t, cursor)
+		assert.Equal(t, []string{"q3", "q2", "q5", "q1", "q4"}, ids)
+	})
+
+	s.T().Run("facets", func(t *testing.T) {
+		result, _ := query(t, MovieQuery{Facets: []string{FacetMaker, FacetGenre, FacetActor}})
+		assert.Equal(t, []*FacetCount{
+			{Value: "Maker A", Count: 2},
+			{Value: "Maker B", Count: 2},
+			{Value: "Maker C", Count: 1},
+		}, result.Facets[FacetMaker])
+		assert.Equal(t, []*FacetCount{
+			{Value: "Drama", Count: 3},
+			{Value: "Comedy", Count: 2},
+		}, result.Facets[FacetGenre])
+		assert.Equal(t, []*FacetCount{
+			{Value: "Actor X", Count: 3},
+			{Value: "Actor Y", Count: 2},
+			{Value: `Actor "Z"`, Count: 1},
+		}, result.Facets[FacetActor])
+	})
+
+	s.T().Run("invalid query", func(t *testing.T) {
+		for _, q := range []MovieQuery{
+			{Sort: "title"},
+			{Facets: []string{"title"}},
+			{Cursor: "invalid"},
+		} {
+			_, err := s.eng.QueryMovies(q)
+			assert.ErrorIs(t, err, ErrInvalidQuery)
+		}
+	})
+}
+
+func (s *DBEngineTestSuite) TestFilmography() {
+	const provider = "FILMOGRAPHY"
+	saveMovie := func(t *testing.T, id, date string, actors ...string) {
+		require.NoError(t, s.eng.SaveMovieInfo(&model.MovieInfo{
+			ID:          id,
+			Number:      "FILM-" + id,
+			Title:       "Film " + id,
+			Provider:    provider,
+			Homepage:    "https://example.com/" + id,
+			CoverURL:    "https://example.com/" + id + ".jpg",
+			Actors:      actors,
+			ReleaseDate: parser.ParseDate(date),
+		}))
+	}
+	saveActor := func(t *testing.T, id, name string, aliases ...string) {
+		require.NoError(t, s.eng.SaveActorInfo(&model.ActorInfo{
+			ID:       id,
+			Name:     name,
+			Provider: provider,
+			Homepage: "https://example.com/actor/" + id,
+			Aliases:  aliases,
+		}))
+	}
+	filmography := func(t *testing.T, opts FilmographyOptions) []string {
+		movies, err := s.eng.GetFilmography(opts)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(movies))
+		for _, movie := range movies {
+			ids = append(ids, movie.ID)
+		}
+		return ids
+	}
+
+	saveActor(s.T(), "a1", "Film Actress")
+	saveMovie(s.T(), "m1", "2020-01-01", "Film Actress", "Film Actor")
+	saveMovie(s.T(), "m2", "2022-01-01", "Film Actress", "Film Actress")
+	saveMovie(s.T(), "m3", "2021-01-01", "Film Alias")
+
+	s.T().Run("by actor id", func(t *testing.T) {
+		assert.Equal(t, []string{"m2", "m1"},
+			filmography(t, FilmographyOptions{Provider: provider, ActorID: "a1"}))
+	})
+
+	s.T().Run("by names", func(t *testing.T) {
+		assert.Equal(t, []string{"m2", "m3", "m1"},
+			filmography(t, FilmographyOptions{Names: []string{"Film Actress", "Film Alias"}}))
+	})
+
+	s.T().Run("link actor saved later", func(t *testing.T) {
+		assert.Empty(t, filmography(t, FilmographyOptions{Provider: provider, ActorID: "a2"}))
+		saveActor(t, "a2", "Film Actor")
+		assert.Equal(t, []string{"m1"},
+			filmography(t, FilmographyOptions{Provider: provider, ActorID: "a2"}))
+	})
+
+	s.T().Run("update movie actors", func(t *testing.T) {
+		saveMovie(t, "m1", "2020-01-01", "Film Actor")
+		assert.Equal(t, []string{"m2"},
+			filmography(t, FilmographyOptions{Provider: provider, ActorID: "a1"}))
+	})
+
+	s.T().Run("import movie actors", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		_, err := s.eng.Export(buf, ExportOptions{Kinds: []string{DumpMovie}, Provider: provider})
+		require.NoError(t, err)
+		dump := strings.ReplaceAll(buf.String(), `"Film Alias"`, `"Film Actress"`)
+		_, err = s.eng.Import(strings.NewReader(dump), ImportOptions{Conflict: ConflictOverwrite})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m2", "m3"},
+			filmography(t, FilmographyOptions{Provider: provider, ActorID: "a1"}))
+	})
+}
+
+func (s *DBEngineTestSuite) TestDump() {
+	const provider = "DUMP"
+	for _, id := range []string{"1", "2"} {
+		s.Require().NoError(s.eng.SaveMovieInfo(&model.MovieInfo{
+			ID:       id,
+			Number:   "DUMP-00" + id,
+			Title:    "Title " + id,
+			Provider: provider,
+			Homepage: "https://example.com/" + id,
+			CoverURL: "https://example.com/" + id + ".jpg",
+			Actors:   []string{"Actor A", "Actor B"},
+		}))
+	}
+	s.Require().NoError(s.eng.SaveActorInfo(&model.ActorInfo{
+		ID:       "1",
+		Name:     "Actor A",
+		Provider: provider,
+		Homepage: "https://example.com/actor/1",
+		Aliases:  []string{"Alias"},
+	}))
+
+	export := func(t *testing.T, opts ExportOptions) (*DumpStats, []byte) {
+		buf := &bytes.Buffer{}
+		stats, err := s.eng.Export(buf, opts)
+		require.NoError(t, err)
+		return stats, buf.Bytes()
+	}
+	modify := func(t *testing.T) {
+		info, err := s.eng.GetMovieInfo(providerid.ProviderID{Provider: provider, ID: "1"})
+		require.NoError(t, err)
+		info.Title = "Modified"
+		require.NoError(t, s.eng.SaveMovieInfo(info))
+	}
+	titleOf := func(t *testing.T) string {
+		info, err := s.eng.GetMovieInfo(providerid.ProviderID{Provider: provider, ID: "1"})
+		require.NoError(t, err)
+		return info.Title
+	}
+
+	s.T().Run("export", func(t *testing.T) {
+		stats, data := export(t, ExportOptions{Provider: "dump"})
+		assert.Equal(t, &DumpStats{Movies: 2, Actors: 1}, stats)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 3)
+		record := &DumpRecord{}
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), record))
+		assert.Equal(t, DumpMovie, record.Kind)
+		assert.False(t, record.UpdatedAt.IsZero())
+
+		stats, _ = export(t, ExportOptions{Provider: provider, Kinds: []string{DumpActor}})
+		assert.Equal(t, &DumpStats{Actors: 1}, stats)
+		stats, _ = export(t, ExportOptions{Provider: provider, UpdatedSince: time.Now().Add(time.Hour)})
+		assert.Equal(t, &DumpStats{}, stats)
+		_, err := s.eng.Export(io.Discard, ExportOptions{Kinds: []string{"unknown"}})
+		assert.Error(t, err)
+	})
+
+	s.T().Run("import with conflict policies", func(t *testing.T) {
+		_, data := export(t, ExportOptions{Provider: provider, Gzip: true})
+		modify(t)
+
+		stats, err := s.eng.Import(bytes.NewReader(data), ImportOptions{Conflict: ConflictSkip})
+		require.NoError(t, err)
+		assert.Equal(t, &DumpStats{Skipped: 3}, stats)
+		assert.Equal(t, "Modified", titleOf(t))
+
+		// the dump is older than the modified movie.
+		stats, err = s.eng.Import(bytes.NewReader(data), ImportOptions{Conflict: ConflictNewer})
+		require.NoError(t, err)
+		assert.Equal(t, 3, stats.Skipped)
+		assert.Equal(t, "Modified", titleOf(t))
+
+		stats, err = s.eng.Import(bytes.NewReader(data), ImportOptions{Conflict: ConflictOverwrite})
+		require.NoError(t, err)
+		assert.Equal(t, &DumpStats{Movies: 2, Actors: 1}, stats)
+		assert.Equal(t, "Title 1", titleOf(t))
+		info, err := s.eng.GetMovieInfo(providerid.ProviderID{Provider: provider, ID: "2"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Actor A", "Actor B"}, []string(info.Actors))
+	})
+
+	s.T().Run("export more than a batch", func(t *testing.T) {
+		// ids of the first provider sort after the ones of the second.
+		for _, unit := range []struct {
+			provider, prefix string
+			n                int
+		}{
+			{"AAA", "z", dumpBatchSize},
+			{"BBB", "a", dumpBatchSize / 2},
+		} {
+			for i := range unit.n {
+				id := fmt.Sprintf("%s%03d", unit.prefix, i)
+				require.NoError(t, s.eng.SaveMovieInfo(&model.MovieInfo{
+					ID:       id,
+					Number:   id,
+					Title:    id,
+					Provider: unit.provider,
+					Homepage: "https://example.com/" + id,
+					CoverURL: "https://example.com/" + id + ".jpg",
+				}))
+			}
+		}
+		_, data := export(t, ExportOptions{Kinds: []string{DumpMovie}})
+		counts := make(map[string]int)
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			record := &DumpRecord{}
+			require.NoError(t, json.Unmarshal([]byte(line), record))
+			info := &model.MovieInfo{}
+			require.NoError(t, json.Unmarshal(record.Data, info))
+			counts[info.Provider]++
+		}
+		assert.Equal(t, dumpBatchSize, counts["AAA"])
+		assert.Equal(t, dumpBatchSize/2, counts["BBB"])
+
+		stats, err := s.eng.Import(bytes.NewReader(data), ImportOptions{Conflict: ConflictOverwrite})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, stats.Movies, 3*dumpBatchSize/2)
+	})
+
+	s.T().Run("import invalid records", func(t *testing.T) {
+		_, err := s.eng.Import(strings.NewReader(`{"kind":"unknown","data":{}}`), ImportOptions{})
+		assert.Error(t, err)
+		_, err = s.eng.Import(strings.NewReader(`{"kind":"movie","data":{"id":"1"}}`), ImportOptions{})
+		assert.Error(t, err)
+		_, err = s.eng.Import(strings.NewReader(""), ImportOptions{Conflict: "unknown"})
+		assert.Error(t, err)
+	})
+}
+
+func TestSaveWithoutActorLinks(t *testing.T) {
+	db, err := database.Open(&database.Config{
+		DSN:                  ":memory:",
+		DisableAutomaticPing: true,
+		LogLevel:             logger.Silent,
+	})
+	require.NoError(t, err)
+	eng := New(db)
+	require.NoError(t, eng.AutoMigrate())
+	// as if the migration of the actor links is pending.
+	require.NoError(t, db.Migrator().DropTable(&model.MovieActor{}))
+
+	const provider = "NOLINKS"
+	assert.Error(t, eng.SaveMovieInfo(&model.MovieInfo{
+		ID:       "1",
+		Number:   "LINK-001",
+		Title:    "Title",
+		Provider: provider,
+		Homepage: "https://example.com/1",
+		CoverURL: "https://example.com/1.jpg",
+		Actors:   []string{"Actor A"},
+	}))
+	assert.Error(t, eng.SaveActorInfo(&model.ActorInfo{
+		ID:       "1",
+		Name:     "Actor A",
+		Provider: provider,
+		Homepage: "https://example.com/actor/1",
+	}))
+
+	// the infos are saved regardless of the links.
+	movie, err := eng.GetMovieInfo(providerid.ProviderID{Provider: provider, ID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "LINK-001", movie.Number)
+	actor, err := eng.GetActorInfo(providerid.ProviderID{Provider: provider, ID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Actor A", actor.Name)
+}
+
 func jsonify(v interface{}) string {
 	data, _ := json.MarshalIndent(v, "", "\t")
 	return string(data)
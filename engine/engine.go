@@ -2,16 +2,20 @@ package engine
 
 import (
 	"fmt"
+	"io"
 	"log"
 	gomaps "maps"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/metatube-community/metatube-sdk-go/collection/maps"
+	"github.com/metatube-community/metatube-sdk-go/common/diskcache"
 	"github.com/metatube-community/metatube-sdk-go/common/fetch"
 	"github.com/metatube-community/metatube-sdk-go/database"
 	mt "github.com/metatube-community/metatube-sdk-go/provider"
@@ -27,8 +31,17 @@ type Engine struct {
 	name    string
 	timeout time.Duration
 	fetcher *fetch.Fetcher
+	// Fetcher for user-supplied URLs
+	untrustedFetcher *fetch.Fetcher
+	// Name:*fetch.Fetcher guarded fetchers of providers
+	guardedFetchers sync.Map
+	// Extra hosts allowed for user-supplied image URLs
+	allowedImageHosts []string
+	// Cache of downloaded source images
+	imageSourceCache *diskcache.Cache
 	// Engine Logger
-	logger *log.Logger
+	logger    *log.Logger
+	logOutput io.Writer
 	// Name:Config Case-Insensitive Map
 	actorProviderConfigs *maps.CaseInsensitiveMap[mt.Config]
 	movieProviderConfigs *maps.CaseInsensitiveMap[mt.Config]
@@ -49,6 +62,8 @@ func New(db *gorm.DB, opts ...Option) *Engine {
 		db:      db,
 		name:    DefaultEngineName,
 		timeout: DefaultRequestTimeout,
+		// log to stdout by default.
+		logOutput: os.Stdout,
 		// pre-initialize case-insensitive maps.
 		actorProviderConfigs: maps.NewCaseInsensitiveMap[mt.Config](),
 		movieProviderConfigs: maps.NewCaseInsensitiveMap[mt.Config](),
@@ -159,6 +174,24 @@ func (e *Engine) Fetch(url string, provider mt.Provider) (*http.Response, error)
 	return e.fetcher.Fetch(url)
 }
 
+// FetchWithOptions is like Fetch, but applies request options, e.g.,
+// headers. Options are ignored if the provider's fetcher doesn't
+// support them.
+func (e *Engine) FetchWithOptions(url string, provider mt.Provider, opts ...fetch.Option) (*http.Response, error) {
+	if fetcher, ok := provider.(mt.Fetcher); ok {
+		if f, ok := fetcher.(optionsFetcher); ok {
+			return f.Get(url, opts...)
+		}
+		return fetcher.Fetch(url)
+	}
+	return e.fetcher.Get(url, opts...)
+}
+
+// optionsFetcher is implemented by *fetch.Fetcher.
+type optionsFetcher interface {
+	Get(url string, opts ...fetch.Option) (*http.Response, error)
+}
+
 // String returns the name of the Engine instance.
 func (e *Engine) String() string { return e.name }
 
@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"github.com/lib/pq"
+
+	"github.com/metatube-community/metatube-sdk-go/model"
+	"github.com/metatube-community/metatube-sdk-go/translate"
+)
+
+// LoadGlossary adds all actor names and aliases in the database to
+// the glossary, so they will be protected in translation.
+func (e *Engine) LoadGlossary(g *translate.Glossary) error {
+	var rows []struct {
+		Name    string
+		Aliases pq.StringArray `gorm:"type:text[]"`
+	}
+	if err := e.db.
+		Model(&model.ActorInfo{}).
+		Select("name", "aliases").
+		Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		g.Add(row.Name, nil)
+		for _, alias := range row.Aliases {
+			g.Add(alias, nil)
+		}
+	}
+	return nil
+}
@@ -1,12 +1,16 @@
 package engine
 
 import (
+	"bytes"
 	"image"
+	"io"
+	"net/http"
 
 	"github.com/metatube-community/metatube-sdk-go/common/number"
 	R "github.com/metatube-community/metatube-sdk-go/constant"
 	"github.com/metatube-community/metatube-sdk-go/detector"
 	"github.com/metatube-community/metatube-sdk-go/engine/providerid"
+	"github.com/metatube-community/metatube-sdk-go/errors"
 	"github.com/metatube-community/metatube-sdk-go/imageutil"
 	"github.com/metatube-community/metatube-sdk-go/model"
 	mt "github.com/metatube-community/metatube-sdk-go/provider"
@@ -18,8 +22,17 @@ const (
 	defaultMoviePrimaryImagePosition  = 1.0
 	defaultMovieThumbImagePosition    = 0.5
 	defaultMovieBackdropImagePosition = 0.0
+	defaultMovieFanartImagePosition   = 0.5
 )
 
+// noCropImageRatio is an invalid ratio that disables cropping.
+const noCropImageRatio = -1
+
+// maxImageSourceSize is the max size of source images to download.
+const maxImageSourceSize = 32 << 20 // 32 MiB
+
+var ErrImageTooLarge = errors.New(http.StatusBadGateway, "image too large")
+
 func (e *Engine) GetActorPrimaryImage(pid providerid.ProviderID) (image.Image, error) {
 	info, err := e.GetActorInfoByProviderID(pid, true)
 	if err != nil {
@@ -75,10 +88,82 @@ func (e *Engine) GetMovieBackdropImage(pid providerid.ProviderID) (image.Image,
 	)
 }
 
-func (e *Engine) GetImageByURL(provider mt.Provider, url string, ratio, pos float64, auto bool) (img image.Image, err error) {
-	if img, err = e.getImageByURL(provider, url); err != nil {
-		return
+func (e *Engine) GetMoviePreviewImage(pid providerid.ProviderID, index int) (image.Image, error) {
+	info, err := e.GetMovieInfoByProviderID(pid, true)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(info.PreviewImages) {
+		return nil, mt.ErrImageNotFound
+	}
+	return e.GetImageByURL(
+		e.MustGetMovieProviderByName(pid.Provider), info.PreviewImages[index],
+		noCropImageRatio, 0, false,
+	)
+}
+
+// GetMovieFanartImage returns a landscape image of the movie, the first
+// preview image is preferred, and falls back to the cover if no previews.
+func (e *Engine) GetMovieFanartImage(pid providerid.ProviderID) (image.Image, error) {
+	url, info, err := e.getPreferredMovieImageURLAndInfo(pid, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.PreviewImages) > 0 {
+		url = info.PreviewImages[0]
+	}
+	return e.GetImageByURL(
+		e.MustGetMovieProviderByName(pid.Provider), url,
+		R.ThumbImageRatio, defaultMovieFanartImagePosition, false,
+	)
+}
+
+func (e *Engine) GetImageByURL(provider mt.Provider, url string, ratio, pos float64, auto bool) (image.Image, error) {
+	img, err := e.fetchImage(url, provider, e.Fetch)
+	if err != nil {
+		return nil, err
+	}
+	return e.processImage(provider, img, ratio, pos, auto), nil
+}
+
+// fetchImage fetches and decodes the source image from url. The data is
+// cached after being decoded if the source cache is enabled, so that
+// different crops of the same image share one download.
+func (e *Engine) fetchImage(url string, provider mt.Provider, fetch func(string, mt.Provider) (*http.Response, error)) (image.Image, error) {
+	key := provider.Name() + ":" + url
+	if e.imageSourceCache != nil {
+		if data, ok := e.imageSourceCache.Get(key); ok {
+			if img, _, err := imageutil.Decode(bytes.NewReader(data)); err == nil {
+				return img, nil
+			}
+			e.imageSourceCache.Delete(key) // corrupted.
+		}
 	}
+	resp, err := fetch(url, provider)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSourceSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImageSourceSize {
+		return nil, ErrImageTooLarge
+	}
+	img, _, err := imageutil.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if e.imageSourceCache != nil {
+		if err = e.imageSourceCache.Set(key, data); err != nil {
+			e.logger.Printf("Cache image source error: %v", err)
+		}
+	}
+	return img, nil
+}
+
+func (e *Engine) processImage(provider mt.Provider, img image.Image, ratio, pos float64, auto bool) image.Image {
 	if auto {
 		// only turn on advanced for movie providers.
 		advancedMode := e.IsMovieProvider(provider.Name())
@@ -87,17 +172,7 @@ func (e *Engine) GetImageByURL(provider mt.Provider, url string, ratio, pos floa
 			pos = axisR // override the default position with detected position.
 		}
 	}
-	return imageutil.CropImagePosition(img, ratio, pos), nil
-}
-
-func (e *Engine) getImageByURL(provider mt.Provider, url string) (img image.Image, err error) {
-	resp, err := e.Fetch(url, provider)
-	if err != nil {
-		return
-	}
-	defer resp.Body.Close()
-	img, _, err = imageutil.Decode(resp.Body)
-	return
+	return imageutil.CropImagePosition(img, ratio, pos)
 }
 
 func (e *Engine) getPreferredMovieImageURLAndInfo(pid providerid.ProviderID, thumb bool) (url string, info *model.MovieInfo, err error) {
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"image"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/metatube-community/metatube-sdk-go/common/fetch"
+	"github.com/metatube-community/metatube-sdk-go/errors"
+	mt "github.com/metatube-community/metatube-sdk-go/provider"
+)
+
+var ErrHostNotAllowed = errors.New(http.StatusForbidden, "host not allowed")
+
+// GetImageByUntrustedURL is like GetImageByURL, but the url is supplied
+// by users, so it must be allowed by the image host policy and must not
+// point to any private addresses.
+func (e *Engine) GetImageByUntrustedURL(provider mt.Provider, url string, ratio, pos float64, auto bool) (image.Image, error) {
+	// check before looking up the source cache.
+	if _, err := e.checkUntrustedURL(provider, url); err != nil {
+		return nil, err
+	}
+	img, err := e.fetchImage(url, provider, func(url string, provider mt.Provider) (*http.Response, error) {
+		return e.FetchUntrusted(url, provider)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e.processImage(provider, img, ratio, pos, auto), nil
+}
+
+// FetchUntrusted fetches the user-supplied url on behalf of the provider.
+func (e *Engine) FetchUntrusted(rawURL string, provider mt.Provider, opts ...fetch.Option) (*http.Response, error) {
+	if _, err := e.checkUntrustedURL(provider, rawURL); err != nil {
+		return nil, err
+	}
+	return e.FetchGuarded(rawURL, provider, opts...)
+}
+
+// FetchGuarded fetches the url on behalf of the provider, connections to
+// private addresses are refused. Unlike FetchUntrusted, the host of the
+// url is not checked, so the url must originate from the provider, e.g.,
+// the segments of its HLS playlists.
+func (e *Engine) FetchGuarded(rawURL string, provider mt.Provider, opts ...fetch.Option) (*http.Response, error) {
+	return e.untrustedFetcherOf(provider).Get(rawURL, opts...)
+}
+
+// guardableFetcher is implemented by providers embedding *fetch.Fetcher.
+type guardableFetcher interface {
+	Guarded() *fetch.Fetcher
+}
+
+// untrustedFetcherOf returns the fetcher for user-supplied urls of the
+// provider. Providers with their own fetchers get guarded copies, so that
+// their headers are kept while private addresses are refused at dial time.
+func (e *Engine) untrustedFetcherOf(provider mt.Provider) *fetch.Fetcher {
+	f, ok := provider.(guardableFetcher)
+	if !ok {
+		return e.untrustedFetcher
+	}
+	if v, ok := e.guardedFetchers.Load(provider.Name()); ok {
+		return v.(*fetch.Fetcher)
+	}
+	v, _ := e.guardedFetchers.LoadOrStore(provider.Name(), f.Guarded())
+	return v.(*fetch.Fetcher)
+}
+
+func (e *Engine) checkUntrustedURL(provider mt.Provider, rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, mt.ErrInvalidURL
+	}
+	if !e.IsHostAllowed(provider, u.Hostname()) {
+		return nil, ErrHostNotAllowed
+	}
+	return u, nil
+}
+
+// IsHostAllowed reports whether media resources can be fetched from the
+// host for the provider. Allowed hosts are the provider's own domain, the
+// hosts declared by the provider and the extra hosts set by options.
+func (e *Engine) IsHostAllowed(provider mt.Provider, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	providerHost := strings.ToLower(provider.URL().Hostname())
+	if host == providerHost {
+		return true
+	}
+	// same registrable domain, e.g. pics.dmm.co.jp for www.dmm.co.jp.
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(providerHost); err == nil &&
+		matchHost(host, "*."+domain) {
+		return true
+	}
+
+	var patterns []string
+	if d, ok := provider.(mt.MediaHostsDeclarer); ok {
+		patterns = append(patterns, d.MediaHosts()...)
+	}
+	patterns = append(patterns, e.allowedImageHosts...)
+	for _, pattern := range patterns {
+		if matchHost(host, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHost matches host against pattern, a leading "*." in the pattern
+// matches the domain itself and all its subdomains.
+func matchHost(host, pattern string) bool {
+	pattern = strings.ToLower(pattern)
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	}
+	return host == pattern
+}
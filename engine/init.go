@@ -2,7 +2,6 @@ package engine
 
 import (
 	"log"
-	"os"
 
 	"github.com/metatube-community/metatube-sdk-go/common/fetch"
 	mt "github.com/metatube-community/metatube-sdk-go/provider"
@@ -17,11 +16,15 @@ func (e *Engine) init() *Engine {
 }
 
 func (e *Engine) initLogger() {
-	e.logger = log.New(os.Stdout, "[ENGINE]\u0020", log.LstdFlags|log.Llongfile)
+	e.logger = log.New(e.logOutput, "[ENGINE]\u0020", log.LstdFlags|log.Llongfile)
 }
 
 func (e *Engine) initFetcher() {
 	e.fetcher = fetch.Default(&fetch.Config{Timeout: e.timeout})
+	e.untrustedFetcher = fetch.Default(&fetch.Config{
+		Timeout:              e.timeout,
+		DisallowPrivateAddrs: true,
+	})
 }
 
 // initActorProviders initializes actor providers.
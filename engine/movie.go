@@ -7,12 +7,11 @@ import (
 	"sync"
 	"time"
 
-	"gorm.io/gorm/clause"
-
 	"github.com/metatube-community/metatube-sdk-go/collection/sets"
 	"github.com/metatube-community/metatube-sdk-go/collection/slices"
 	"github.com/metatube-community/metatube-sdk-go/common/comparer"
 	"github.com/metatube-community/metatube-sdk-go/common/number"
+	"github.com/metatube-community/metatube-sdk-go/engine/dbengine"
 	"github.com/metatube-community/metatube-sdk-go/engine/providerid"
 	"github.com/metatube-community/metatube-sdk-go/model"
 	mt "github.com/metatube-community/metatube-sdk-go/provider"
@@ -223,9 +222,8 @@ func (e *Engine) getMovieInfoWithCallback(provider mt.MovieProvider, id string,
 	// delayed info auto-save.
 	defer func() {
 		if err == nil && info.IsValid() {
-			e.db.Clauses(clause.OnConflict{
-				UpdateAll: true,
-			}).Create(info) // ignore error
+			// save with the actor links.
+			_ = dbengine.New(e.db).SaveMovieInfo(info) // ignore error
 		}
 	}()
 	return callback()
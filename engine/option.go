@@ -1,8 +1,11 @@
 package engine
 
 import (
+	"io"
+	"strings"
 	"time"
 
+	"github.com/metatube-community/metatube-sdk-go/common/diskcache"
 	mt "github.com/metatube-community/metatube-sdk-go/provider"
 )
 
@@ -14,12 +17,38 @@ func WithEngineName(name string) Option {
 	}
 }
 
+// WithLogOutput sets the output destination of the engine logger.
+func WithLogOutput(w io.Writer) Option {
+	return func(e *Engine) {
+		e.logOutput = w
+	}
+}
+
 func WithRequestTimeout(timeout time.Duration) Option {
 	return func(e *Engine) {
 		e.timeout = timeout
 	}
 }
 
+// WithAllowedImageHosts allows extra hosts for user-supplied image URLs,
+// a leading "*." matches the domain and all its subdomains.
+func WithAllowedImageHosts(hosts ...string) Option {
+	return func(e *Engine) {
+		for _, host := range hosts {
+			if host = strings.TrimSpace(host); host != "" {
+				e.allowedImageHosts = append(e.allowedImageHosts, host)
+			}
+		}
+	}
+}
+
+// WithImageSourceCache caches downloaded source images on disk.
+func WithImageSourceCache(c *diskcache.Cache) Option {
+	return func(e *Engine) {
+		e.imageSourceCache = c
+	}
+}
+
 func WithActorProviderConfig(name string, config mt.Config) Option {
 	return func(e *Engine) {
 		e.actorProviderConfigs.Set(name, config)
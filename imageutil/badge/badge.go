@@ -3,6 +3,7 @@ package badge
 import (
 	"fmt"
 	"image"
+	"net/http"
 	"time"
 
 	"github.com/jellydator/ttlcache/v3"
@@ -16,20 +17,30 @@ var (
 		ttlcache.WithTTL[string, image.Image](30*time.Minute),
 		ttlcache.WithCapacity[string, image.Image](10),
 	)
-	badgeFetcher = fetch.Default(nil)
+	badgeFetcher = fetch.Default(&fetch.Config{DisallowPrivateAddrs: true})
 )
 
+// FetchFunc fetches the badge image of the url.
+type FetchFunc func(url string) (*http.Response, error)
+
 func init() {
 	// start badge cache.
 	go badgeCache.Start()
 }
 
+// Badge watermarks src with the badge image fetched from the url,
+// connections to private addresses are refused.
 func Badge(src image.Image, badge string) (image.Image, error) {
+	return BadgeWithFetch(src, badge, badgeFetcher.Fetch)
+}
+
+// BadgeWithFetch is like Badge, but fetches the badge image with fn.
+func BadgeWithFetch(src image.Image, badge string, fn FetchFunc) (image.Image, error) {
 	var img image.Image
 	if item := badgeCache.Get(badge); item != nil {
 		img = item.Value()
 	} else {
-		resp, err := badgeFetcher.Fetch(badge)
+		resp, err := fn(badge)
 		if err != nil {
 			return nil, fmt.Errorf("fetch badge: %w", err)
 		}
@@ -3,9 +3,82 @@ package imageutil
 import (
 	"image"
 	"image/jpeg"
+	"image/png"
 	"io"
+	"strings"
+
+	"github.com/gen2brain/avif"
+	"github.com/gen2brain/jpegli"
+	"github.com/gen2brain/webp"
+)
+
+// Encoder encodes m to w with quality in range [1, 100].
+type Encoder func(w io.Writer, m image.Image, quality int) error
+
+// Format is an image output format.
+type Format struct {
+	Name     string
+	MIMEType string
+	Encode   Encoder
+}
+
+// Supported output formats.
+var (
+	FormatJPEG   = &Format{Name: "jpeg", MIMEType: "image/jpeg", Encode: EncodeToJPEG}
+	FormatJPEGLI = &Format{Name: "jpegli", MIMEType: "image/jpeg", Encode: EncodeToJPEGLI}
+	FormatWebP   = &Format{Name: "webp", MIMEType: "image/webp", Encode: EncodeToWebP}
+	FormatAVIF   = &Format{Name: "avif", MIMEType: "image/avif", Encode: EncodeToAVIF}
+	FormatPNG    = &Format{Name: "png", MIMEType: "image/png", Encode: EncodeToPNG}
 )
 
+// LookupFormat returns the output format by its name.
+func LookupFormat(name string) (*Format, bool) {
+	switch strings.ToLower(name) {
+	case "jpeg", "jpg":
+		return FormatJPEG, true
+	case "jpegli":
+		return FormatJPEGLI, true
+	case "webp":
+		return FormatWebP, true
+	case "avif":
+		return FormatAVIF, true
+	case "png":
+		return FormatPNG, true
+	}
+	return nil, false
+}
+
 func EncodeToJPEG(w io.Writer, m image.Image, quality int) error {
 	return jpeg.Encode(w, m, &jpeg.Options{Quality: quality})
 }
+
+// EncodeToJPEGLI encodes m with jpegli, which produces smaller JPEG
+// files than the standard library at the same quality.
+func EncodeToJPEGLI(w io.Writer, m image.Image, quality int) error {
+	return jpegli.Encode(w, m, &jpegli.EncodingOptions{
+		Quality:              quality,
+		ChromaSubsampling:    image.YCbCrSubsampleRatio420,
+		ProgressiveLevel:     2,
+		OptimizeCoding:       true,
+		AdaptiveQuantization: true,
+	})
+}
+
+func EncodeToWebP(w io.Writer, m image.Image, quality int) error {
+	return webp.Encode(w, m, webp.Options{Quality: quality})
+}
+
+func EncodeToAVIF(w io.Writer, m image.Image, quality int) error {
+	return avif.Encode(w, m, avif.Options{
+		Quality:      quality,
+		QualityAlpha: quality,
+		// AVIF encoding is slow, prefer speed for realtime serving.
+		Speed:             8,
+		ChromaSubsampling: image.YCbCrSubsampleRatio420,
+	})
+}
+
+// EncodeToPNG encodes m losslessly, quality is ignored.
+func EncodeToPNG(w io.Writer, m image.Image, _ int) error {
+	return png.Encode(w, m)
+}
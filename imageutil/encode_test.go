@@ -0,0 +1,33 @@
+package imageutil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 48; y++ {
+			m.Set(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 5), B: 128, A: 255})
+		}
+	}
+	for _, name := range []string{"jpeg", "jpg", "jpegli", "webp", "avif", "png"} {
+		format, ok := LookupFormat(name)
+		require.True(t, ok, name)
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, format.Encode(buf, m, 90), name)
+
+		img, _, err := Decode(buf)
+		require.NoError(t, err, name)
+		assert.Equal(t, m.Bounds().Size(), img.Bounds().Size(), name)
+	}
+	_, ok := LookupFormat("gif")
+	assert.False(t, ok)
+}
@@ -2,6 +2,8 @@ package imageutil
 
 import (
 	"image"
+	"math"
+	"strings"
 
 	"github.com/disintegration/imaging"
 )
@@ -18,3 +20,58 @@ func Resize(src image.Image, width, height int) image.Image {
 	}
 	return imaging.Resize(src, width, height, imaging.Lanczos)
 }
+
+// Fit specifies how an image is resized to fit the given box.
+type Fit uint8
+
+const (
+	// FitCover scales the image to cover the box, and crops the
+	// overflowed parts at center.
+	FitCover Fit = iota
+	// FitContain scales the image to fit inside the box.
+	FitContain
+	// FitFill stretches the image to fill the box.
+	FitFill
+)
+
+// ParseFit parses fit mode by name, empty name means FitCover.
+func ParseFit(name string) (Fit, bool) {
+	switch strings.ToLower(name) {
+	case "", "cover":
+		return FitCover, true
+	case "contain":
+		return FitContain, true
+	case "fill":
+		return FitFill, true
+	}
+	return 0, false
+}
+
+// ResizeFit resizes src to the box of width and height with fit mode.
+// If either width or height is 0, the aspect ratio is preserved and fit
+// mode is ignored.
+func ResizeFit(src image.Image, width, height int, fit Fit) image.Image {
+	if width == 0 || height == 0 {
+		return Resize(src, width, height)
+	}
+	srcW, srcH := float64(src.Bounds().Dx()), float64(src.Bounds().Dy())
+	scaleW, scaleH := float64(width)/srcW, float64(height)/srcH
+	switch fit {
+	case FitCover:
+		scale := max(scaleW, scaleH)
+		img := Resize(src,
+			max(width, int(math.Round(srcW*scale))),
+			max(height, int(math.Round(srcH*scale))))
+		return CropImage(img, image.Rect(0, 0, width, height).Add(image.Pt(
+			(img.Bounds().Dx()-width)/2,
+			(img.Bounds().Dy()-height)/2,
+		)).Add(img.Bounds().Min))
+	case FitContain:
+		scale := min(scaleW, scaleH)
+		return Resize(src,
+			max(1, int(math.Round(srcW*scale))),
+			max(1, int(math.Round(srcH*scale))))
+	default: // FitFill
+		return Resize(src, width, height)
+	}
+}
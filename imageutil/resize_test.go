@@ -0,0 +1,28 @@
+package imageutil
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResizeFit(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	for _, unit := range []struct {
+		w, h  int
+		fit   Fit
+		wantW int
+		wantH int
+	}{
+		{300, 300, FitCover, 300, 300},
+		{300, 300, FitContain, 300, 225},
+		{300, 300, FitFill, 300, 300},
+		{400, 0, FitContain, 400, 300},
+		{0, 150, FitCover, 200, 150},
+		{0, 0, FitFill, 800, 600},
+	} {
+		img := ResizeFit(src, unit.w, unit.h, unit.fit)
+		assert.Equal(t, image.Pt(unit.wantW, unit.wantH), img.Bounds().Size(), unit)
+	}
+}
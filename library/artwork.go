@@ -0,0 +1,246 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/metatube-community/metatube-sdk-go/engine/providerid"
+	"github.com/metatube-community/metatube-sdk-go/imageutil"
+	"github.com/metatube-community/metatube-sdk-go/imageutil/badge"
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+// Sidecar artwork names expected by players, e.g. Kodi, Jellyfin. If
+// the directory holds several movies, artworks are prefixed by the file
+// names instead, e.g. ABP-030-poster.jpg.
+const (
+	PosterName      = "poster.jpg"
+	FanartName      = "fanart.jpg"
+	ThumbName       = "thumb.jpg"
+	ExtraFanartName = "extrafanart"
+)
+
+const (
+	// DefaultArtworkQuality is the default JPEG quality of artworks,
+	// same as the image routes.
+	DefaultArtworkQuality = 90
+	// DefaultExtraFanart is the default max number of preview images
+	// written to extrafanart/.
+	DefaultExtraFanart = 10
+	// subtitleBadge is the built-in badge of subtitled movies.
+	subtitleBadge = "zimu.png"
+)
+
+// ArtworkGetter gets movie images with the engine image pipeline, it's
+// implemented by *engine.Engine.
+type ArtworkGetter interface {
+	MovieGetter
+	GetMoviePrimaryImage(pid providerid.ProviderID, ratio, pos float64) (image.Image, error)
+	GetMovieThumbImage(pid providerid.ProviderID) (image.Image, error)
+	GetMovieBackdropImage(pid providerid.ProviderID) (image.Image, error)
+	GetMoviePreviewImage(pid providerid.ProviderID, index int) (image.Image, error)
+}
+
+// ArtworkReport is the result of writing artworks.
+type ArtworkReport struct {
+	Written []string `json:"written"`
+	// Skipped is the number of artworks already up to date.
+	Skipped int               `json:"skipped"`
+	Failed  map[string]string `json:"failed,omitempty"`
+}
+
+// artwork is a sidecar image and its getter.
+type artwork struct {
+	path string
+	get  func() (image.Image, error)
+}
+
+// ArtworkWriter writes sidecar artworks next to matched files.
+type ArtworkWriter struct {
+	lib    *Library
+	images ArtworkGetter
+
+	quality     int
+	extraFanart int
+	force       bool
+}
+
+type ArtworkOption func(*ArtworkWriter)
+
+// WithArtworkQuality sets the JPEG quality of artworks.
+func WithArtworkQuality(quality int) ArtworkOption {
+	return func(w *ArtworkWriter) {
+		w.quality = quality
+	}
+}
+
+// WithExtraFanart sets the max number of preview images written to
+// extrafanart/, zero disables extra fanart.
+func WithExtraFanart(n int) ArtworkOption {
+	return func(w *ArtworkWriter) {
+		w.extraFanart = n
+	}
+}
+
+// WithArtworkForce rewrites artworks even if they are up to date.
+func WithArtworkForce(force bool) ArtworkOption {
+	return func(w *ArtworkWriter) {
+		w.force = force
+	}
+}
+
+func NewArtworkWriter(lib *Library, images ArtworkGetter, opts ...ArtworkOption) *ArtworkWriter {
+	w := &ArtworkWriter{
+		lib:         lib,
+		images:      images,
+		quality:     DefaultArtworkQuality,
+		extraFanart: DefaultExtraFanart,
+	}
+	// apply options.
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Write writes artworks of all matched and manually resolved files.
+func (w *ArtworkWriter) Write(ctx context.Context) (*ArtworkReport, error) {
+	files, err := w.lib.Files(StatusMatched, StatusManual)
+	if err != nil {
+		return nil, err
+	}
+	report := &ArtworkReport{Failed: make(map[string]string)}
+	shared := sharedDirs(files)
+	for _, file := range files {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err = w.writeFile(ctx, file, shared[filepath.Dir(file.Path)], report); err != nil {
+			report.Failed[file.Path] = err.Error()
+		}
+	}
+	return report, nil
+}
+
+// WriteFile writes artworks next to the file, and adds the results to
+// the report. Artworks newer than the movie info are skipped.
+func (w *ArtworkWriter) WriteFile(ctx context.Context, file *File, report *ArtworkReport) error {
+	files, err := w.lib.Files(StatusMatched, StatusManual)
+	if err != nil {
+		return err
+	}
+	return w.writeFile(ctx, file, sharedDirs(files)[filepath.Dir(file.Path)], report)
+}
+
+// writeFile writes artworks of the file, shared is true if other movies
+// are in the same directory, whose artworks are then named after the
+// file, and extra fanart is skipped since it is per directory.
+func (w *ArtworkWriter) writeFile(ctx context.Context, file *File, shared bool, report *ArtworkReport) error {
+	pid, err := providerid.New(file.Provider, file.MovieID)
+	if err != nil {
+		return err
+	}
+	info, err := w.images.GetMovieInfoByProviderID(pid, true)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(file.Path)
+	artworks := []artwork{
+		{artworkPath(file, PosterName, shared), func() (image.Image, error) {
+			img, err := w.images.GetMoviePrimaryImage(pid, -1, -1)
+			if err != nil || !file.Subtitle {
+				return img, err
+			}
+			return badge.Badge(img, subtitleBadge)
+		}},
+		{artworkPath(file, FanartName, shared), func() (image.Image, error) {
+			return w.images.GetMovieBackdropImage(pid)
+		}},
+		{artworkPath(file, ThumbName, shared), func() (image.Image, error) {
+			return w.images.GetMovieThumbImage(pid)
+		}},
+	}
+	extraFanart := w.extraFanart
+	if shared {
+		extraFanart = 0
+	}
+	for i := range min(len(info.PreviewImages), extraFanart) {
+		artworks = append(artworks, artwork{
+			filepath.Join(dir, ExtraFanartName, fmt.Sprintf("fanart%d.jpg", i+1)),
+			func() (image.Image, error) { return w.images.GetMoviePreviewImage(pid, i) },
+		})
+	}
+
+	var errs []error
+	for _, artwork := range artworks {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if !w.force && isUpToDate(artwork.path, info) {
+			report.Skipped++
+			continue
+		}
+		img, err := artwork.get()
+		if err == nil {
+			err = w.writeImage(artwork.path, img)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(artwork.path), err))
+			continue
+		}
+		report.Written = append(report.Written, artwork.path)
+	}
+	return errors.Join(errs...)
+}
+
+// writeImage writes the image as JPEG atomically.
+func (w *ArtworkWriter) writeImage(path string, img image.Image) error {
+	return writeFileAtomic(path, func(f io.Writer) error {
+		return imageutil.EncodeToJPEGLI(f, img, w.quality)
+	})
+}
+
+// isUpToDate reports whether the artwork exists and is newer than the
+// movie info.
+// artworkPath returns the path of the artwork next to the file, it is
+// prefixed by the file name if the directory is shared.
+func artworkPath(file *File, name string, shared bool) string {
+	if !shared {
+		return filepath.Join(filepath.Dir(file.Path), name)
+	}
+	return strings.TrimSuffix(file.Path, filepath.Ext(file.Path)) + "-" + name
+}
+
+// sharedDirs returns the directories holding more than one movie.
+func sharedDirs(files []*File) map[string]bool {
+	movies := make(map[string]map[string]struct{})
+	for _, file := range files {
+		dir := filepath.Dir(file.Path)
+		if movies[dir] == nil {
+			movies[dir] = make(map[string]struct{})
+		}
+		movies[dir][file.Provider+":"+file.MovieID] = struct{}{}
+	}
+	shared := make(map[string]bool)
+	for dir, ids := range movies {
+		if len(ids) > 1 {
+			shared[dir] = true
+		}
+	}
+	return shared
+}
+
+func isUpToDate(path string, info *model.MovieInfo) bool {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return !stat.ModTime().Before(info.UpdatedAt)
+}
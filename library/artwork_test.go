@@ -0,0 +1,139 @@
+package library
+
+import (
+	"context"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metatube-community/metatube-sdk-go/engine/providerid"
+	mt "github.com/metatube-community/metatube-sdk-go/provider"
+)
+
+type mockArtworkGetter struct {
+	mockMovieGetter
+	calls int
+}
+
+func (m *mockArtworkGetter) image(pid providerid.ProviderID, width, height int) (image.Image, error) {
+	if _, ok := m.mockMovieGetter[pid]; !ok {
+		return nil, mt.ErrImageNotFound
+	}
+	m.calls++
+	return image.NewRGBA(image.Rect(0, 0, width, height)), nil
+}
+
+func (m *mockArtworkGetter) GetMoviePrimaryImage(pid providerid.ProviderID, _, _ float64) (image.Image, error) {
+	return m.image(pid, 300, 450)
+}
+
+func (m *mockArtworkGetter) GetMovieThumbImage(pid providerid.ProviderID) (image.Image, error) {
+	return m.image(pid, 400, 225)
+}
+
+func (m *mockArtworkGetter) GetMovieBackdropImage(pid providerid.ProviderID) (image.Image, error) {
+	return m.image(pid, 800, 538)
+}
+
+func (m *mockArtworkGetter) GetMoviePreviewImage(pid providerid.ProviderID, index int) (image.Image, error) {
+	if index >= len(m.mockMovieGetter[pid].PreviewImages) {
+		return nil, mt.ErrImageNotFound
+	}
+	return m.image(pid, 400, 300)
+}
+
+func TestArtworkWriter(t *testing.T) {
+	lib := newTestLibrary(t, mockSearcher{
+		"ABP-030": {{Provider: "JavBus", ID: "ABP-030", Number: "ABP-030"}},
+	})
+	images := &mockArtworkGetter{mockMovieGetter: mockMovieGetter{
+		{Provider: "JavBus", ID: "ABP-030"}: {
+			Number:        "ABP-030",
+			PreviewImages: []string{"1.jpg", "2.jpg", "3.jpg"},
+		},
+	}}
+
+	root := t.TempDir()
+	touch(t, filepath.Join(root, "ABP-030", "ABP-030-C.mp4"))
+	_, err := lib.Scan(context.Background(), root)
+	require.NoError(t, err)
+
+	w := NewArtworkWriter(lib, images, WithExtraFanart(2))
+	report, err := w.Write(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Failed)
+	assert.Len(t, report.Written, 5)
+	for name, size := range map[string]image.Point{
+		PosterName: {300, 450},
+		FanartName: {800, 538},
+		ThumbName:  {400, 225},
+		filepath.Join(ExtraFanartName, "fanart1.jpg"): {400, 300},
+		filepath.Join(ExtraFanartName, "fanart2.jpg"): {400, 300},
+	} {
+		f, err := os.Open(filepath.Join(root, "ABP-030", name))
+		require.NoError(t, err)
+		cfg, err := jpeg.DecodeConfig(f)
+		f.Close()
+		require.NoError(t, err, name)
+		assert.Equal(t, size, image.Pt(cfg.Width, cfg.Height), name)
+	}
+	assert.NoFileExists(t, filepath.Join(root, "ABP-030", ExtraFanartName, "fanart3.jpg"))
+
+	// up to date artworks are skipped.
+	images.calls = 0
+	report, err = w.Write(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Written)
+	assert.Equal(t, 5, report.Skipped)
+	assert.Zero(t, images.calls)
+
+	// rewritten if the movie info is updated.
+	images.mockMovieGetter[providerid.ProviderID{Provider: "JavBus", ID: "ABP-030"}].UpdatedAt = time.Now().Add(time.Hour)
+	report, err = w.Write(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, report.Written, 5)
+}
+
+func TestArtworkWriterSharedDir(t *testing.T) {
+	lib := newTestLibrary(t, mockSearcher{
+		"ABP-030": {{Provider: "JavBus", ID: "ABP-030", Number: "ABP-030"}},
+		"IPX-177": {{Provider: "JavBus", ID: "IPX-177", Number: "IPX-177"}},
+	})
+	images := &mockArtworkGetter{mockMovieGetter: mockMovieGetter{
+		{Provider: "JavBus", ID: "ABP-030"}: {Number: "ABP-030", PreviewImages: []string{"1.jpg"}},
+		{Provider: "JavBus", ID: "IPX-177"}: {Number: "IPX-177", PreviewImages: []string{"1.jpg"}},
+	}}
+
+	root := t.TempDir()
+	touch(t, filepath.Join(root, "ABP-030.mp4"))
+	touch(t, filepath.Join(root, "IPX-177.mkv"))
+	_, err := lib.Scan(context.Background(), root)
+	require.NoError(t, err)
+
+	report, err := NewArtworkWriter(lib, images).Write(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Failed)
+	assert.Len(t, report.Written, 6)
+	for _, stem := range []string{"ABP-030", "IPX-177"} {
+		for _, name := range []string{PosterName, FanartName, ThumbName} {
+			assert.FileExists(t, filepath.Join(root, stem+"-"+name))
+		}
+	}
+	// per directory artworks are never written.
+	assert.NoFileExists(t, filepath.Join(root, PosterName))
+	assert.NoDirExists(t, filepath.Join(root, ExtraFanartName))
+
+	// the same for a single file.
+	files, err := lib.Files(StatusMatched)
+	require.NoError(t, err)
+	report = &ArtworkReport{Failed: make(map[string]string)}
+	require.NoError(t, NewArtworkWriter(lib, images, WithArtworkForce(true)).WriteFile(context.Background(), files[0], report))
+	assert.Len(t, report.Written, 3)
+	assert.NoFileExists(t, filepath.Join(root, PosterName))
+}
@@ -0,0 +1,69 @@
+package library
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/metatube-community/metatube-sdk-go/common/number"
+)
+
+// Name is the parsed filename of a movie file.
+type Name struct {
+	Number string `json:"number"`
+	// Part is the 1-based part number of multi-part movies, 0 if
+	// the movie is not split.
+	Part int `json:"part,omitempty"`
+	// Subtitle is true if the movie has hardcoded subtitles.
+	Subtitle bool `json:"subtitle,omitempty"`
+}
+
+var (
+	suffixSepRegexp = regexp.MustCompile(`[-_.\s\[\]()]+`)
+	partRegexp      = regexp.MustCompile(`(?i)[-_.\s](?:cd|part|pt|disc|disk)[-_\s]?(\d{1,2})(?:$|[-_.\s])`)
+	// subtitle markers, e.g. ABP-030-C, ABP-030C, rctd-460ch.
+	subtitleTokens = []string{"c", "uc", "ch"}
+)
+
+// ParseFilename extracts the movie number, part and subtitle marker
+// from the filename. Note that a single C suffix is always treated as
+// subtitle marker, while A, B and D are treated as parts.
+func ParseFilename(filename string) *Name {
+	base := filepath.Base(filename)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	name := &Name{}
+	// trim part markers first, since not all of them are trimmed by
+	// number.Trim, e.g. -part1.
+	if ss := partRegexp.FindStringSubmatchIndex(stem); ss != nil {
+		name.Part, _ = strconv.Atoi(stem[ss[2]:ss[3]])
+		stem = stem[:ss[0]] + stem[ss[3]:]
+	}
+	if name.Number = number.Trim(stem + ext); name.Number == "" {
+		return name
+	}
+	if strings.Contains(stem, "中文字幕") {
+		name.Subtitle = true
+	}
+
+	// parse the suffixes after the number only.
+	suffix := stem
+	if i := strings.Index(strings.ToUpper(stem), strings.ToUpper(name.Number)); i >= 0 {
+		suffix = stem[i+len(name.Number):]
+	}
+	for _, token := range suffixSepRegexp.Split(suffix, -1) {
+		token = strings.ToLower(token)
+		switch {
+		case token == "":
+			continue
+		case slices.Contains(subtitleTokens, token):
+			name.Subtitle = true
+		case name.Part == 0 && (token == "a" || token == "b" || token == "d"):
+			name.Part = int(token[0]-'a') + 1
+		}
+	}
+	return name
+}
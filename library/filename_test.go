@@ -0,0 +1,35 @@
+package library
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilename(t *testing.T) {
+	for _, unit := range []struct {
+		filename string
+		want     Name
+	}{
+		{"ABP-030.mp4", Name{Number: "ABP-030"}},
+		{"/movies/ABP-030.mp4", Name{Number: "ABP-030"}},
+		{"ABP-030-C.mp4", Name{Number: "ABP-030", Subtitle: true}},
+		{"ABP-030C.mkv", Name{Number: "ABP-030", Subtitle: true}},
+		{"ABP-030_C.mkv", Name{Number: "ABP-030", Subtitle: true}},
+		{"ABP-030-UC.mp4", Name{Number: "ABP-030", Subtitle: true}},
+		{"rctd-460ch.mp4", Name{Number: "rctd-460", Subtitle: true}},
+		{"ABP-030-cd1.mp4", Name{Number: "ABP-030", Part: 1}},
+		{"ABP-030-CD2.mp4", Name{Number: "ABP-030", Part: 2}},
+		{"ABP-030-part3.mp4", Name{Number: "ABP-030", Part: 3}},
+		{"ABP-030-C-cd2.mp4", Name{Number: "ABP-030", Part: 2, Subtitle: true}},
+		{"ABP-030A.mp4", Name{Number: "ABP-030", Part: 1}},
+		{"ABP-030-B.mp4", Name{Number: "ABP-030", Part: 2}},
+		{"[98t.tv]vema-181-4k-C.mp4", Name{Number: "vema-181", Subtitle: true}},
+		{"ABP-030 中文字幕.mp4", Name{Number: "ABP-030", Subtitle: true}},
+		{"HEYZO-1234.mp4", Name{Number: "HEYZO-1234"}},
+		{"FC2-PPV-1292936.mp4", Name{Number: "FC2-1292936"}},
+		{"readme.txt", Name{Number: "readme"}},
+	} {
+		assert.Equal(t, unit.want, *ParseFilename(unit.filename), unit.filename)
+	}
+}
@@ -0,0 +1,362 @@
+// Package library organises local movie files, it scans directories
+// for movie files and matches them with the engine.
+package library
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+const LibraryFilesTableName = "library_files"
+
+// Status is the match status of a file.
+type Status string
+
+const (
+	StatusMatched   Status = "matched"
+	StatusAmbiguous Status = "ambiguous"
+	StatusUnmatched Status = "unmatched"
+	// StatusManual is set by manual review.
+	StatusManual Status = "manual"
+)
+
+// File is a scanned movie file, it is also the DB model of the
+// file to movie mappings.
+type File struct {
+	Path       string    `json:"path" gorm:"primaryKey"`
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"mod_time"`
+	Name       `gorm:"embedded"`
+	Status     Status  `json:"status" gorm:"index"`
+	Provider   string  `json:"provider,omitempty"`
+	MovieID    string  `json:"movie_id,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+	// Candidates are kept for the manual review of ambiguous matches.
+	Candidates        datatypes.JSONType[[]*Candidate] `json:"candidates,omitempty"`
+	model.TimeTracker `json:"-"`
+}
+
+func (*File) TableName() string {
+	return LibraryFilesTableName
+}
+
+// Searcher searches movies with all providers, it's implemented by
+// *engine.Engine.
+type Searcher interface {
+	SearchMovieAll(keyword string, fallback bool) ([]*model.MovieSearchResult, error)
+}
+
+// Report is the result of a scan.
+type Report struct {
+	Matched   []*File `json:"matched"`
+	Ambiguous []*File `json:"ambiguous"`
+	Unmatched []*File `json:"unmatched"`
+	// Skipped is the number of unchanged files.
+	Skipped int `json:"skipped"`
+	// Removed is the number of files no longer exist.
+	Removed int `json:"removed"`
+	// Failed maps the paths that cannot be read to the errors.
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+func (r *Report) fail(path string, err error) {
+	if r.Failed == nil {
+		r.Failed = make(map[string]string)
+	}
+	r.Failed[path] = err.Error()
+}
+
+type Library struct {
+	db       *gorm.DB
+	searcher Searcher
+
+	extensions     []string
+	matchThreshold float64
+	minConfidence  float64
+	concurrency    int
+}
+
+func New(db *gorm.DB, searcher Searcher, opts ...Option) *Library {
+	lib := &Library{
+		db:             db,
+		searcher:       searcher,
+		extensions:     DefaultExtensions,
+		matchThreshold: DefaultMatchThreshold,
+		minConfidence:  DefaultMinConfidence,
+		concurrency:    DefaultConcurrency,
+	}
+	// apply options.
+	for _, opt := range opts {
+		opt(lib)
+	}
+	return lib
+}
+
+// Scan walks the roots for movie files, and matches new or changed
+// files. Unmatched files are always retried, and the mappings of
+// removed files are deleted.
+func (lib *Library) Scan(ctx context.Context, roots ...string) (*Report, error) {
+	report := &Report{}
+	for _, root := range roots {
+		if err := lib.scan(ctx, root, report); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func (lib *Library) scan(ctx context.Context, root string, report *Report) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	var existing []*File
+	prefix := escapeLike(strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator)) + "%"
+	if err = lib.db.Where(`path LIKE ? ESCAPE '\'`, prefix).Find(&existing).Error; err != nil {
+		return err
+	}
+	prevFiles := make(map[string]*File, len(existing))
+	for _, file := range existing {
+		prevFiles[file.Path] = file
+	}
+
+	var pending []*File
+	seen := make(map[string]bool)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// skip hidden directories, e.g. .actors, .git.
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !lib.isMovieFile(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		seen[path] = true
+		if prev, ok := prevFiles[path]; ok &&
+			prev.Status != StatusUnmatched &&
+			prev.Size == info.Size() &&
+			prev.ModTime.Equal(info.ModTime()) {
+			report.Skipped++
+			return nil
+		}
+		pending = append(pending, &File{
+			Path:    path,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Name:    *ParseFilename(d.Name()),
+		})
+		return ctx.Err()
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = lib.matchFiles(ctx, pending, report); err != nil {
+		return err
+	}
+	var removed []string
+	for path := range prevFiles {
+		if !seen[path] {
+			removed = append(removed, path)
+		}
+	}
+	if len(removed) > 0 {
+		if err = lib.db.Delete(&File{}, "path IN ?", removed).Error; err != nil {
+			return err
+		}
+		report.Removed += len(removed)
+	}
+	return nil
+}
+
+// ScanFiles matches the given files, e.g. new files reported by the
+// Watcher. Unchanged files are skipped unless unmatched, same as Scan.
+// Files no longer existing are skipped, and files that cannot be read
+// are reported as failed, neither fails the other files.
+func (lib *Library) ScanFiles(ctx context.Context, paths ...string) (*Report, error) {
+	report := &Report{}
+	var pending []*File
+	for _, path := range paths {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			report.fail(path, err)
+			continue
+		}
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			report.fail(path, err)
+			continue
+		}
+		if info.IsDir() || !lib.isMovieFile(info.Name()) {
+			continue
+		}
+		prev := &File{}
+		if err = lib.db.Limit(1).Find(prev, "path = ?", path).Error; err != nil {
+			return nil, err
+		}
+		if prev.Path != "" &&
+			prev.Status != StatusUnmatched &&
+			prev.Size == info.Size() &&
+			prev.ModTime.Equal(info.ModTime()) {
+			report.Skipped++
+			continue
+		}
+		pending = append(pending, &File{
+			Path:    path,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Name:    *ParseFilename(info.Name()),
+		})
+	}
+	if err := lib.matchFiles(ctx, pending, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// matchFiles matches and saves the pending files, and adds them to
+// the report.
+func (lib *Library) matchFiles(ctx context.Context, pending []*File, report *Report) error {
+	// match files concurrently, since each match searches all providers.
+	var (
+		err error
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, max(lib.concurrency, 1))
+	)
+	for _, file := range pending {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			lib.match(file)
+			mu.Lock()
+			defer mu.Unlock()
+			switch file.Status {
+			case StatusMatched:
+				report.Matched = append(report.Matched, file)
+			case StatusAmbiguous:
+				report.Ambiguous = append(report.Ambiguous, file)
+			default:
+				report.Unmatched = append(report.Unmatched, file)
+			}
+		}()
+	}
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		if err = lib.db.Clauses(clause.OnConflict{
+			UpdateAll: true,
+		}).CreateInBatches(pending, 100).Error; err != nil {
+			return err
+		}
+	}
+	for _, files := range [][]*File{report.Matched, report.Ambiguous, report.Unmatched} {
+		slices.SortFunc(files, func(a, b *File) int { return strings.Compare(a.Path, b.Path) })
+	}
+	return nil
+}
+
+// match matches the file with search results, and updates its status.
+func (lib *Library) match(file *File) {
+	file.Status = StatusUnmatched
+	if file.Number == "" {
+		return
+	}
+	results, err := lib.searcher.SearchMovieAll(file.Number, true)
+	if err != nil {
+		return // not found or search failed, retry next scan.
+	}
+	candidates := rankCandidates(file.Number, results, lib.minConfidence)
+	if len(candidates) == 0 {
+		return
+	}
+	best := candidates[0]
+	file.Provider, file.MovieID, file.Confidence = best.Provider, best.ID, best.Confidence
+	if best.Confidence >= lib.matchThreshold && !isAmbiguous(candidates) {
+		file.Status = StatusMatched
+		return
+	}
+	file.Status = StatusAmbiguous
+	file.Candidates = datatypes.NewJSONType(candidates)
+}
+
+// Files lists the files of the given statuses, or all files if none.
+func (lib *Library) Files(statuses ...Status) ([]*File, error) {
+	var files []*File
+	tx := lib.db.Order("path")
+	if len(statuses) > 0 {
+		tx = tx.Where("status IN ?", statuses)
+	}
+	if err := tx.Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Resolve maps the file to the movie manually, it's kept by later scans
+// unless the file changes.
+func (lib *Library) Resolve(path, provider, id string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	tx := lib.db.Model(&File{}).Where("path = ?", path).Updates(map[string]any{
+		"status":     StatusManual,
+		"provider":   provider,
+		"movie_id":   id,
+		"confidence": 1,
+		"candidates": datatypes.NewJSONType[[]*Candidate](nil),
+	})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (lib *Library) isMovieFile(name string) bool {
+	// skip hidden files, e.g. macOS ._ files.
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	return slices.Contains(lib.extensions, strings.ToLower(filepath.Ext(name)))
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
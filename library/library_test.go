@@ -0,0 +1,171 @@
+package library
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metatube-community/metatube-sdk-go/common/number"
+	"github.com/metatube-community/metatube-sdk-go/database"
+	"github.com/metatube-community/metatube-sdk-go/database/migrate"
+	"github.com/metatube-community/metatube-sdk-go/model"
+	mt "github.com/metatube-community/metatube-sdk-go/provider"
+)
+
+type mockSearcher map[string][]*model.MovieSearchResult
+
+func (m mockSearcher) SearchMovieAll(keyword string, _ bool) ([]*model.MovieSearchResult, error) {
+	if results, ok := m[number.Trim(keyword)]; ok {
+		return results, nil
+	}
+	return nil, mt.ErrInfoNotFound
+}
+
+func newTestLibrary(t *testing.T, searcher Searcher, opts ...Option) *Library {
+	db, err := database.Open(&database.Config{
+		DSN:                  filepath.Join(t.TempDir(), "library.db"),
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	m, err := migrate.New(db)
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+	return New(db, searcher, opts...)
+}
+
+func touch(t *testing.T, path string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(path), 0o644))
+}
+
+func TestConfidence(t *testing.T) {
+	for _, unit := range []struct {
+		number string
+		result string
+		want   float64
+	}{
+		{"ABP-030", "ABP-030", 1},
+		{"abp030", "ABP-030", 1},
+		{"ABP-30", "ABP-030", 1},
+		{"ABP-030", "118ABP030", 0.95},
+		{"ABP-030", "ABP-031", 0},
+	} {
+		assert.Equal(t, unit.want, confidence(unit.number, &model.MovieSearchResult{Number: unit.result}),
+			"%s vs %s", unit.number, unit.result)
+	}
+	assert.Less(t, confidence("ABP-030", &model.MovieSearchResult{Number: "IPX-177"}), DefaultMinConfidence)
+}
+
+func TestBestMatch(t *testing.T) {
+	results := []*model.MovieSearchResult{
+		{Provider: "JavBus", ID: "ABP-031", Number: "ABP-031"},
+		{Provider: "FANZA", ID: "118abp030", Number: "118ABP030"},
+	}
+	best, ok := BestMatch("ABP-030", results, DefaultMatchThreshold)
+	if assert.True(t, ok) {
+		assert.Equal(t, "118abp030", best.ID)
+	}
+
+	_, ok = BestMatch("IPX-177", results, DefaultMatchThreshold)
+	assert.False(t, ok)
+}
+
+func TestLibraryScan(t *testing.T) {
+	searcher := mockSearcher{
+		"ABP-030": {
+			{Provider: "FANZA", ID: "118abp030", Number: "118ABP030"},
+			{Provider: "JavBus", ID: "ABP-030", Number: "ABP-030"},
+		},
+		"SSIS-001": {
+			{Provider: "FANZA", ID: "ssis00001", Number: "SSIS-001"},
+			{Provider: "JavBus", ID: "SSIS-001", Number: "SSIS-001"},
+		},
+		// fuzzy results of different movies.
+		"ABCD-12": {
+			{Provider: "JavBus", ID: "ABCDE-12", Number: "ABCDE-12"},
+			{Provider: "JavDB", ID: "ABCDF-12", Number: "ABCDF-12"},
+		},
+	}
+	lib := newTestLibrary(t, searcher)
+
+	root := t.TempDir()
+	touch(t, filepath.Join(root, "ABP-030-C-cd1.mp4"))
+	touch(t, filepath.Join(root, "ABP-030-C-cd2.mp4"))
+	touch(t, filepath.Join(root, "sub", "SSIS-001.mkv"))
+	touch(t, filepath.Join(root, "sub", "ABCD-12.mkv"))
+	touch(t, filepath.Join(root, "sub", "IPX-177.mkv"))
+	touch(t, filepath.Join(root, "sub", "IPX-177.nfo"))
+	touch(t, filepath.Join(root, ".hidden", "IPX-178.mp4"))
+
+	report, err := lib.Scan(context.Background(), root)
+	require.NoError(t, err)
+	if assert.Len(t, report.Matched, 3) {
+		assert.Equal(t, filepath.Join(root, "ABP-030-C-cd1.mp4"), report.Matched[0].Path)
+		assert.Equal(t, Name{Number: "ABP-030", Part: 1, Subtitle: true}, report.Matched[0].Name)
+		// exact match is preferred over content id.
+		assert.Equal(t, "JavBus", report.Matched[0].Provider)
+		assert.Equal(t, "ABP-030", report.Matched[0].MovieID)
+		assert.Equal(t, 2, report.Matched[1].Part)
+		assert.Equal(t, "FANZA", report.Matched[2].Provider)
+		assert.Equal(t, "ssis00001", report.Matched[2].MovieID)
+	}
+	if assert.Len(t, report.Ambiguous, 1) {
+		assert.Equal(t, "ABCD-12", report.Ambiguous[0].Number)
+		assert.Len(t, report.Ambiguous[0].Candidates.Data(), 2)
+	}
+	if assert.Len(t, report.Unmatched, 1) {
+		assert.Equal(t, "IPX-177", report.Unmatched[0].Number)
+	}
+
+	// resolve ambiguous file manually.
+	require.NoError(t, lib.Resolve(report.Ambiguous[0].Path, "JavDB", "ABCDF-12"))
+	assert.Error(t, lib.Resolve(filepath.Join(root, "none.mp4"), "JavDB", "ABCDF-12"))
+
+	// rescan: unchanged files are skipped, unmatched are retried.
+	require.NoError(t, os.Remove(filepath.Join(root, "ABP-030-C-cd2.mp4")))
+	searcher["IPX-177"] = []*model.MovieSearchResult{{Provider: "JavBus", ID: "IPX-177", Number: "IPX-177"}}
+	report, err = lib.Scan(context.Background(), root)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Skipped)
+	assert.Equal(t, 1, report.Removed)
+	assert.Len(t, report.Matched, 1)
+
+	// changed files are rematched.
+	path := filepath.Join(root, "sub", "SSIS-001.mkv")
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Hour)))
+	report, err = lib.Scan(context.Background(), root)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Skipped)
+	assert.Len(t, report.Matched, 1)
+
+	files, err := lib.Files(StatusManual)
+	require.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, "ABCDF-12", files[0].MovieID)
+	}
+	files, err = lib.Files()
+	require.NoError(t, err)
+	assert.Len(t, files, 4)
+}
+
+func TestLibraryScanFiles(t *testing.T) {
+	lib := newTestLibrary(t, mockSearcher{
+		"ABP-030": {{Provider: "JavBus", ID: "ABP-030", Number: "ABP-030"}},
+	})
+
+	root := t.TempDir()
+	touch(t, filepath.Join(root, "ABP-030.mp4"))
+
+	// missing files do not fail the others.
+	report, err := lib.ScanFiles(context.Background(),
+		filepath.Join(root, "SSIS-001.mp4"),
+		filepath.Join(root, "ABP-030.mp4"))
+	require.NoError(t, err)
+	assert.Len(t, report.Matched, 1)
+	assert.Empty(t, report.Failed)
+}
@@ -0,0 +1,118 @@
+package library
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/metatube-community/metatube-sdk-go/common/comparer"
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+const (
+	// DefaultMatchThreshold is the min confidence of automatic matches.
+	DefaultMatchThreshold = 0.9
+	// DefaultMinConfidence is the min confidence of ambiguous matches,
+	// candidates below it are dropped.
+	DefaultMinConfidence = 0.5
+	// ambiguityMargin is the confidence margin within which candidates
+	// of different numbers are considered ambiguous.
+	ambiguityMargin = 0.05
+)
+
+// Candidate is a search result scored against the file.
+type Candidate struct {
+	*model.MovieSearchResult
+	Confidence float64 `json:"confidence"`
+}
+
+var (
+	nonAlnumRegexp    = regexp.MustCompile(`[^A-Z\d]+`)
+	digitsRegexp      = regexp.MustCompile(`\d+`)
+	contentIDRegexp   = regexp.MustCompile(`^\d+([A-Z]+\d+)$`)
+	numberPartsRegexp = regexp.MustCompile(`^([A-Z]+)(\d+)$`)
+)
+
+// normalizeNumber normalizes numbers for comparison, e.g. ABP-030,
+// abp030 and ABP_30 are all normalized to ABP30.
+func normalizeNumber(s string) string {
+	s = nonAlnumRegexp.ReplaceAllString(strings.ToUpper(s), "")
+	return digitsRegexp.ReplaceAllStringFunc(s, func(d string) string {
+		n, err := strconv.ParseUint(d, 10, 64)
+		if err != nil {
+			return d
+		}
+		return strconv.FormatUint(n, 10)
+	})
+}
+
+// confidence returns how likely the search result is the movie of number.
+func confidence(number string, result *model.MovieSearchResult) float64 {
+	a, b := normalizeNumber(number), normalizeNumber(result.Number)
+	if a == b {
+		return 1
+	}
+	// DMM content IDs are prefixed with label IDs, e.g. 118ABP030.
+	trimPrefix := func(s string) string {
+		if ss := contentIDRegexp.FindStringSubmatch(s); len(ss) > 1 {
+			return normalizeNumber(ss[1])
+		}
+		return s
+	}
+	if trimPrefix(a) == trimPrefix(b) {
+		return 0.95
+	}
+	// same label but different serial numbers are never the same movie.
+	pa, pb := numberPartsRegexp.FindStringSubmatch(a), numberPartsRegexp.FindStringSubmatch(b)
+	if len(pa) > 0 && len(pb) > 0 && pa[1] == pb[1] {
+		return 0
+	}
+	return comparer.Compare(a, b)
+}
+
+// rankCandidates scores and sorts the search results by confidence,
+// results of the same confidence keep the engine order.
+func rankCandidates(number string, results []*model.MovieSearchResult, minConfidence float64) []*Candidate {
+	candidates := make([]*Candidate, 0, len(results))
+	for _, result := range results {
+		if c := confidence(number, result); c >= minConfidence {
+			candidates = append(candidates, &Candidate{MovieSearchResult: result, Confidence: c})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b *Candidate) int {
+		switch {
+		case a.Confidence > b.Confidence:
+			return -1
+		case a.Confidence < b.Confidence:
+			return 1
+		}
+		return 0
+	})
+	return candidates
+}
+
+// isAmbiguous returns true if any other candidate of a different
+// number is as good as the best one.
+func isAmbiguous(candidates []*Candidate) bool {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if best.Confidence-c.Confidence > ambiguityMargin {
+			break
+		}
+		if normalizeNumber(c.Number) != normalizeNumber(best.Number) {
+			return true
+		}
+	}
+	return false
+}
+
+// BestMatch returns the search result of number if the best one is
+// confident enough, i.e. not below threshold and not ambiguous.
+func BestMatch(number string, results []*model.MovieSearchResult, threshold float64) (*model.MovieSearchResult, bool) {
+	candidates := rankCandidates(number, results, threshold)
+	if len(candidates) == 0 || isAmbiguous(candidates) {
+		return nil, false
+	}
+	return candidates[0].MovieSearchResult, true
+}
@@ -0,0 +1,48 @@
+package library
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NFOPath returns the path of the NFO file next to the movie file, it
+// has the same name as the movie file, which Kodi and Jellyfin expect.
+func NFOPath(file *File) string {
+	return strings.TrimSuffix(file.Path, filepath.Ext(file.Path)) + ".nfo"
+}
+
+// WriteNFO writes the NFO data next to the movie file.
+func WriteNFO(file *File, data []byte) error {
+	return writeFileAtomic(NFOPath(file), func(w io.Writer) error {
+		_, err := io.Copy(w, bytes.NewReader(data))
+		return err
+	})
+}
+
+// writeFileAtomic writes the file with fn atomically, so that players
+// never read partial files.
+func writeFileAtomic(path string, fn func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".metatube-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err = fn(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	// CreateTemp creates files with mode 0600.
+	if err = os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,46 @@
+package library
+
+import (
+	"strings"
+)
+
+// DefaultExtensions are the default extensions of movie files.
+var DefaultExtensions = []string{
+	".3gp", ".avi", ".flv", ".iso", ".m2ts", ".m4v", ".mkv", ".mov",
+	".mp4", ".mpeg", ".mpg", ".rm", ".rmvb", ".ts", ".webm", ".wmv",
+}
+
+// DefaultConcurrency is the default number of files matched at once.
+const DefaultConcurrency = 4
+
+type Option func(*Library)
+
+// WithExtensions sets the extensions of movie files, e.g. ".mp4".
+func WithExtensions(exts ...string) Option {
+	return func(lib *Library) {
+		lib.extensions = nil
+		for _, ext := range exts {
+			if ext = strings.ToLower(strings.TrimSpace(ext)); ext != "" {
+				if !strings.HasPrefix(ext, ".") {
+					ext = "." + ext
+				}
+				lib.extensions = append(lib.extensions, ext)
+			}
+		}
+	}
+}
+
+// WithMatchThreshold sets the min confidence of automatic matches,
+// files below it are reported as ambiguous.
+func WithMatchThreshold(threshold float64) Option {
+	return func(lib *Library) {
+		lib.matchThreshold = threshold
+	}
+}
+
+// WithConcurrency sets the number of files matched at once.
+func WithConcurrency(n int) Option {
+	return func(lib *Library) {
+		lib.concurrency = n
+	}
+}
@@ -0,0 +1,255 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/metatube-community/metatube-sdk-go/engine/providerid"
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+const LibraryMovesTableName = "library_moves"
+
+// maxCollisions is the max number of suffixes tried for a target path.
+const maxCollisions = 100
+
+// MovieGetter gets movie info, it's implemented by *engine.Engine.
+type MovieGetter interface {
+	GetMovieInfoByProviderID(pid providerid.ProviderID, lazy bool) (*model.MovieInfo, error)
+}
+
+// Move is a planned or applied file move, applied moves are kept in
+// the DB as the undo journal.
+type Move struct {
+	ID uint `json:"-" gorm:"primaryKey"`
+	// Batch groups the moves applied at once, undo reverts a batch.
+	Batch  int64  `json:"-" gorm:"index"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Root is the destination directory, empty directories are
+	// removed up to it on undo.
+	Root      string    `json:"-"`
+	Undone    bool      `json:"-"`
+	CreatedAt time.Time `json:"-"`
+}
+
+func (*Move) TableName() string {
+	return LibraryMovesTableName
+}
+
+// Plan is the moves of matched files, files failed to plan are kept
+// in place.
+type Plan struct {
+	Moves  []*Move           `json:"moves"`
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+// Organizer renames and moves matched files into the destination
+// directory with the path template.
+type Organizer struct {
+	lib    *Library
+	movies MovieGetter
+	tmpl   *Template
+	dest   string
+}
+
+func NewOrganizer(lib *Library, movies MovieGetter, tmpl *Template, dest string) *Organizer {
+	return &Organizer{
+		lib:    lib,
+		movies: movies,
+		tmpl:   tmpl,
+		dest:   dest,
+	}
+}
+
+// Plan plans the moves of matched and manually resolved files without
+// touching the filesystem, which is used as the dry run.
+func (o *Organizer) Plan(ctx context.Context) (*Plan, error) {
+	dest, err := filepath.Abs(o.dest)
+	if err != nil {
+		return nil, err
+	}
+	files, err := o.lib.Files(StatusMatched, StatusManual)
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{Failed: make(map[string]string)}
+	taken := make(map[string]bool)
+	for _, file := range files {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		pid, err := providerid.New(file.Provider, file.MovieID)
+		if err != nil {
+			plan.Failed[file.Path] = err.Error()
+			continue
+		}
+		info, err := o.movies.GetMovieInfoByProviderID(pid, true)
+		if err != nil {
+			plan.Failed[file.Path] = err.Error()
+			continue
+		}
+		target := filepath.Join(dest, o.tmpl.Execute(info, &file.Name, filepath.Ext(file.Path)))
+		if target, err = availablePath(target, file.Path, taken); err != nil {
+			plan.Failed[file.Path] = err.Error()
+			continue
+		}
+		taken[target] = true
+		if target == file.Path {
+			continue // already organised.
+		}
+		plan.Moves = append(plan.Moves, &Move{
+			Source: file.Path,
+			Target: target,
+			Root:   dest,
+		})
+	}
+	return plan, nil
+}
+
+// Apply applies the planned moves, and records them in the journal.
+// Moves applied before an error are kept, and can be undone.
+func (o *Organizer) Apply(ctx context.Context, plan *Plan) error {
+	batch := time.Now().UnixNano()
+	for _, move := range plan.Moves {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// the target may be taken since planned.
+		if _, err := os.Lstat(move.Target); !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("target exists: %s", move.Target)
+		}
+		if err := moveFile(move.Source, move.Target); err != nil {
+			return err
+		}
+		move.Batch = batch
+		if err := o.lib.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(move).Error; err != nil {
+				return err
+			}
+			return tx.Model(&File{}).Where("path = ?", move.Source).
+				Update("path", move.Target).Error
+		}); err != nil {
+			// keep the file where the DB says it is.
+			_ = moveFile(move.Target, move.Source)
+			return err
+		}
+	}
+	return nil
+}
+
+// Undo reverts the last applied batch of moves, and returns them.
+func (lib *Library) Undo(ctx context.Context) ([]*Move, error) {
+	last := &Move{}
+	if err := lib.db.Where("undone = ?", false).Order("batch DESC").Take(last).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil // nothing to undo.
+		}
+		return nil, err
+	}
+	var moves []*Move
+	if err := lib.db.Where("batch = ? AND undone = ?", last.Batch, false).
+		Order("id DESC").Find(&moves).Error; err != nil {
+		return nil, err
+	}
+	for i, move := range moves {
+		if err := ctx.Err(); err != nil {
+			return moves[:i], err
+		}
+		if _, err := os.Lstat(move.Source); !errors.Is(err, os.ErrNotExist) {
+			return moves[:i], fmt.Errorf("source exists: %s", move.Source)
+		}
+		if err := moveFile(move.Target, move.Source); err != nil {
+			return moves[:i], err
+		}
+		if err := lib.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(move).Update("undone", true).Error; err != nil {
+				return err
+			}
+			return tx.Model(&File{}).Where("path = ?", move.Target).
+				Update("path", move.Source).Error
+		}); err != nil {
+			return moves[:i], err
+		}
+		removeEmptyDirs(filepath.Dir(move.Target), move.Root)
+	}
+	return moves, nil
+}
+
+// availablePath returns the target, or the target with a " (n)" suffix
+// if it's taken by other files.
+func availablePath(target, source string, taken map[string]bool) (string, error) {
+	ext := filepath.Ext(target)
+	stem := strings.TrimSuffix(target, ext)
+	for i := 1; i <= maxCollisions; i++ {
+		if !taken[target] {
+			if _, err := os.Lstat(target); errors.Is(err, os.ErrNotExist) || target == source {
+				return target, nil
+			}
+		}
+		suffix := fmt.Sprintf(" (%d)", i)
+		base := truncateName(filepath.Base(stem)+suffix+ext, suffix+ext)
+		target = filepath.Join(filepath.Dir(stem), base)
+	}
+	return "", fmt.Errorf("too many collisions: %s", target)
+}
+
+// moveFile renames the file, it copies the file across filesystems.
+func moveFile(source, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	err := os.Rename(source, target)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err = copyFile(source, target); err != nil {
+		_ = os.Remove(target)
+		return err
+	}
+	return os.Remove(source)
+}
+
+func copyFile(source, target string) error {
+	src, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(target, info.ModTime(), info.ModTime())
+}
+
+// removeEmptyDirs removes the dir and its parents if empty, up to
+// but excluding the root.
+func removeEmptyDirs(dir, root string) {
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		if os.Remove(dir) != nil {
+			return // not empty.
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,103 @@
+package library
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metatube-community/metatube-sdk-go/engine/providerid"
+	"github.com/metatube-community/metatube-sdk-go/model"
+	mt "github.com/metatube-community/metatube-sdk-go/provider"
+)
+
+type mockMovieGetter map[providerid.ProviderID]*model.MovieInfo
+
+func (m mockMovieGetter) GetMovieInfoByProviderID(pid providerid.ProviderID, _ bool) (*model.MovieInfo, error) {
+	if info, ok := m[pid]; ok {
+		return info, nil
+	}
+	return nil, mt.ErrInfoNotFound
+}
+
+func TestOrganizer(t *testing.T) {
+	lib := newTestLibrary(t, mockSearcher{
+		"ABP-030":  {{Provider: "JavBus", ID: "ABP-030", Number: "ABP-030"}},
+		"SSIS-001": {{Provider: "JavBus", ID: "SSIS-001", Number: "SSIS-001"}},
+		"IPX-177":  {{Provider: "JavBus", ID: "IPX-177", Number: "IPX-177"}},
+	})
+	movies := mockMovieGetter{
+		{Provider: "JavBus", ID: "ABP-030"}:  {Number: "ABP-030", Title: "Title", Maker: "Prestige"},
+		{Provider: "JavBus", ID: "SSIS-001"}: {Number: "SSIS-001", Title: "Title", Maker: "S1"},
+	}
+
+	root := t.TempDir()
+	dest := filepath.Join(root, "organized")
+	touch(t, filepath.Join(root, "ABP-030-cd1.mp4"))
+	touch(t, filepath.Join(root, "ABP-030-cd2.mp4"))
+	touch(t, filepath.Join(root, "sub", "SSIS-001.mkv"))
+	touch(t, filepath.Join(root, "IPX-177.mp4"))
+	// taken by another file.
+	touch(t, filepath.Join(dest, "S1", "SSIS-001 Title", "SSIS-001.mkv"))
+
+	_, err := lib.Scan(context.Background(), root)
+	require.NoError(t, err)
+
+	tmpl, err := ParseTemplate(DefaultTemplate)
+	require.NoError(t, err)
+	organizer := NewOrganizer(lib, movies, tmpl, dest)
+	plan, err := organizer.Plan(context.Background())
+	require.NoError(t, err)
+	want := []*Move{
+		{Source: filepath.Join(root, "ABP-030-cd1.mp4"), Target: filepath.Join(dest, "Prestige", "ABP-030 Title", "ABP-030-cd1.mp4")},
+		{Source: filepath.Join(root, "ABP-030-cd2.mp4"), Target: filepath.Join(dest, "Prestige", "ABP-030 Title", "ABP-030-cd2.mp4")},
+		{Source: filepath.Join(root, "sub", "SSIS-001.mkv"), Target: filepath.Join(dest, "S1", "SSIS-001 Title", "SSIS-001 (1).mkv")},
+	}
+	if assert.Len(t, plan.Moves, len(want)) {
+		for i, move := range plan.Moves {
+			assert.Equal(t, want[i].Source, move.Source)
+			assert.Equal(t, want[i].Target, move.Target)
+		}
+	}
+	assert.Contains(t, plan.Failed, filepath.Join(root, "IPX-177.mp4"))
+	// dry run never touches files.
+	assert.FileExists(t, filepath.Join(root, "ABP-030-cd1.mp4"))
+
+	require.NoError(t, organizer.Apply(context.Background(), plan))
+	for _, move := range want {
+		assert.NoFileExists(t, move.Source)
+		assert.FileExists(t, move.Target)
+	}
+	files, err := lib.Files(StatusMatched)
+	require.NoError(t, err)
+	for _, file := range files {
+		assert.NotEqual(t, filepath.Join(root, "ABP-030-cd1.mp4"), file.Path)
+	}
+
+	// organised files are not moved again.
+	plan, err = organizer.Plan(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, plan.Moves)
+
+	moves, err := lib.Undo(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, moves, 3)
+	for _, move := range want {
+		assert.FileExists(t, move.Source)
+		assert.NoFileExists(t, move.Target)
+	}
+	// empty directories are removed, others are kept.
+	assert.NoDirExists(t, filepath.Join(dest, "Prestige"))
+	assert.FileExists(t, filepath.Join(dest, "S1", "SSIS-001 Title", "SSIS-001.mkv"))
+
+	files, err = lib.Files(StatusMatched)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "ABP-030-cd1.mp4"), files[0].Path)
+
+	// nothing left to undo.
+	moves, err = lib.Undo(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, moves)
+}
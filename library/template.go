@@ -0,0 +1,193 @@
+package library
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+// DefaultTemplate is the default path template of organised files.
+const DefaultTemplate = "{maker}/{number} {title}/{number}{part}{sub}{ext}"
+
+const (
+	// MaxNameBytes is the max length of each path component, which is
+	// the limit of most filesystems.
+	MaxNameBytes = 255
+	// maxTitleRunes is the max length of titles in paths, since titles
+	// can be very long and are placed amid other fields.
+	maxTitleRunes = 80
+	// unknownName replaces the path components that render empty.
+	unknownName = "Unknown"
+)
+
+// templateFields are the values of the template placeholders.
+var templateFields = map[string]func(*model.MovieInfo, *Name, string) string{
+	"number":   func(info *model.MovieInfo, _ *Name, _ string) string { return info.Number },
+	"id":       func(info *model.MovieInfo, _ *Name, _ string) string { return info.ID },
+	"provider": func(info *model.MovieInfo, _ *Name, _ string) string { return info.Provider },
+	"title": func(info *model.MovieInfo, _ *Name, _ string) string {
+		return truncateRunes(info.Title, maxTitleRunes)
+	},
+	"maker":    func(info *model.MovieInfo, _ *Name, _ string) string { return info.Maker },
+	"label":    func(info *model.MovieInfo, _ *Name, _ string) string { return info.Label },
+	"series":   func(info *model.MovieInfo, _ *Name, _ string) string { return info.Series },
+	"director": func(info *model.MovieInfo, _ *Name, _ string) string { return info.Director },
+	"actor": func(info *model.MovieInfo, _ *Name, _ string) string {
+		if len(info.Actors) > 0 {
+			return info.Actors[0]
+		}
+		return ""
+	},
+	"actors": func(info *model.MovieInfo, _ *Name, _ string) string { return strings.Join(info.Actors, ", ") },
+	"year": func(info *model.MovieInfo, _ *Name, _ string) string {
+		if date := time.Time(info.ReleaseDate); !date.IsZero() {
+			return strconv.Itoa(date.Year())
+		}
+		return ""
+	},
+	"date": func(info *model.MovieInfo, _ *Name, _ string) string {
+		if date := time.Time(info.ReleaseDate); !date.IsZero() {
+			return date.Format(time.DateOnly)
+		}
+		return ""
+	},
+	"part": func(_ *model.MovieInfo, name *Name, _ string) string {
+		if name.Part > 0 {
+			return "-cd" + strconv.Itoa(name.Part)
+		}
+		return ""
+	},
+	"sub": func(_ *model.MovieInfo, name *Name, _ string) string {
+		if name.Subtitle {
+			return "-C"
+		}
+		return ""
+	},
+	"ext": func(_ *model.MovieInfo, _ *Name, ext string) string { return ext },
+}
+
+// Template is a path template of organised files, e.g. DefaultTemplate.
+// Placeholders are enclosed in braces, and slashes separate directories.
+type Template struct {
+	tokens []templateToken
+}
+
+type templateToken struct {
+	text    string
+	isField bool
+}
+
+// ParseTemplate parses the path template, it returns an error if any
+// placeholder is unknown or the braces are unbalanced.
+func ParseTemplate(s string) (*Template, error) {
+	t := &Template{}
+	for s != "" {
+		i := strings.IndexAny(s, "{}")
+		if i < 0 {
+			t.tokens = append(t.tokens, templateToken{text: s})
+			break
+		}
+		if s[i] == '}' {
+			return nil, fmt.Errorf("unexpected } in template at: %s", s[i:])
+		}
+		if i > 0 {
+			t.tokens = append(t.tokens, templateToken{text: s[:i]})
+		}
+		j := strings.IndexAny(s[i+1:], "{}")
+		if j < 0 || s[i+1+j] != '}' {
+			return nil, fmt.Errorf("unclosed { in template at: %s", s[i:])
+		}
+		field := s[i+1 : i+1+j]
+		if _, ok := templateFields[field]; !ok {
+			return nil, fmt.Errorf("unknown template field: {%s}", field)
+		}
+		t.tokens = append(t.tokens, templateToken{text: field, isField: true})
+		s = s[i+j+2:]
+	}
+	if len(t.tokens) == 0 {
+		return nil, fmt.Errorf("empty template")
+	}
+	return t, nil
+}
+
+// Execute renders the relative path of the movie file, ext is the file
+// extension. Field values never introduce new directories, and each path
+// component is sanitised and limited to MaxNameBytes.
+func (t *Template) Execute(info *model.MovieInfo, name *Name, ext string) string {
+	// render with a placeholder for template separators.
+	const sep = "\x00"
+	sb := &strings.Builder{}
+	for _, token := range t.tokens {
+		if token.isField {
+			sb.WriteString(sanitizeName(templateFields[token.text](info, name, ext)))
+		} else {
+			sb.WriteString(strings.ReplaceAll(token.text, "/", sep))
+		}
+	}
+	components := strings.Split(sb.String(), sep)
+	for i, component := range components {
+		component = cleanName(component)
+		if component == "" {
+			component = unknownName
+		}
+		if i == len(components)-1 {
+			// keep the extension of file names.
+			component = truncateName(component, filepath.Ext(component))
+		} else {
+			component = truncateName(component, "")
+		}
+		components[i] = component
+	}
+	return filepath.Join(components...)
+}
+
+// sanitizeName replaces the characters that are invalid in filenames
+// on common filesystems, including path separators.
+func sanitizeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r):
+			return -1
+		case strings.ContainsRune(`<>:"/\|?*`, r):
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// cleanName collapses spaces, and trims spaces and dots that are not
+// allowed at the ends of names on Windows.
+func cleanName(s string) string {
+	s = strings.Join(strings.Fields(sanitizeName(s)), " ")
+	s = strings.TrimRight(s, ". ")
+	if s == "." || s == ".." {
+		return ""
+	}
+	return s
+}
+
+// truncateName truncates the name to MaxNameBytes, the suffix is kept.
+func truncateName(s, suffix string) string {
+	if len(s) <= MaxNameBytes || len(suffix) >= MaxNameBytes {
+		return s
+	}
+	stem := strings.TrimSuffix(s, suffix)
+	n := MaxNameBytes - len(suffix)
+	for n > 0 && !utf8.RuneStart(stem[n]) {
+		n--
+	}
+	return strings.TrimRight(stem[:n], ". ") + suffix
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:n]))
+}
@@ -0,0 +1,81 @@
+package library
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+func TestParseTemplate(t *testing.T) {
+	for _, s := range []string{
+		DefaultTemplate,
+		"{number}{ext}",
+		"{year}/{actor}/{id} [{provider}]{ext}",
+	} {
+		_, err := ParseTemplate(s)
+		assert.NoError(t, err, s)
+	}
+	for _, s := range []string{
+		"",
+		"{unknown}{ext}",
+		"{number{ext}",
+		"{number}}{ext}",
+		"{number",
+	} {
+		_, err := ParseTemplate(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestTemplateExecute(t *testing.T) {
+	info := &model.MovieInfo{
+		ID:          "118abp030",
+		Number:      "ABP-030",
+		Title:       `A/B: "Title"?`,
+		Provider:    "FANZA",
+		Maker:       "Prestige.",
+		Actors:      []string{"Actor A", "Actor B"},
+		ReleaseDate: datatypes.Date(time.Date(2013, 5, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	for _, unit := range []struct {
+		tmpl string
+		name Name
+		want string
+	}{
+		{DefaultTemplate, Name{}, "Prestige/ABP-030 A_B_ _Title__/ABP-030.mp4"},
+		{DefaultTemplate, Name{Part: 2, Subtitle: true}, "Prestige/ABP-030 A_B_ _Title__/ABP-030-cd2-C.mp4"},
+		{"{year}/{date} {actor}/{number}{ext}", Name{}, "2013/2013-05-01 Actor A/ABP-030.mp4"},
+		{"{label}/{actors}/{number}{ext}", Name{}, "Unknown/Actor A, Actor B/ABP-030.mp4"},
+		{"{provider}/../{id}{ext}", Name{}, "FANZA/Unknown/118abp030.mp4"},
+	} {
+		tmpl, err := ParseTemplate(unit.tmpl)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.FromSlash(unit.want), tmpl.Execute(info, &unit.name, ".mp4"), unit.tmpl)
+	}
+
+	// long names are truncated, keeping the extension.
+	tmpl, err := ParseTemplate("{series}/{director}{ext}")
+	require.NoError(t, err)
+	info.Series = strings.Repeat("系列", 200)
+	info.Director = strings.Repeat("d", 300)
+	components := strings.Split(tmpl.Execute(info, &Name{}, ".mkv"), string(filepath.Separator))
+	if assert.Len(t, components, 2) {
+		assert.LessOrEqual(t, len(components[0]), MaxNameBytes)
+		assert.True(t, strings.HasPrefix(components[0], "系列"))
+		assert.Len(t, components[1], MaxNameBytes)
+		assert.True(t, strings.HasSuffix(components[1], ".mkv"))
+	}
+
+	// long titles are truncated by runes.
+	info.Title = strings.Repeat("标题", 100)
+	tmpl, err = ParseTemplate("{title}{ext}")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("标题", 40)+".mp4", tmpl.Execute(info, &Name{}, ".mp4"))
+}
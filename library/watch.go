@@ -0,0 +1,413 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/metatube-community/metatube-sdk-go/common/fetch"
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+const LibraryFailuresTableName = "library_failures"
+
+const (
+	// DefaultDebounce is the default time a file must stay unchanged
+	// before being matched, so that partial writes are never matched.
+	DefaultDebounce = 30 * time.Second
+	// DefaultRetryInterval is the default interval of retrying failures.
+	DefaultRetryInterval = time.Hour
+	// webhookTimeout is the timeout of webhook requests.
+	webhookTimeout = 10 * time.Second
+)
+
+// StageMatch is the failure stage of unmatched files, other stages are
+// named after the watch hooks.
+const StageMatch = "match"
+
+// Failure is a failed stage of a watched file, it's retried by the
+// Watcher until it succeeds or the file is removed.
+type Failure struct {
+	Path              string `json:"path" gorm:"primaryKey"`
+	Stage             string `json:"stage" gorm:"primaryKey"`
+	Error             string `json:"error"`
+	Attempts          int    `json:"attempts"`
+	model.TimeTracker `json:"-"`
+}
+
+func (*Failure) TableName() string {
+	return LibraryFailuresTableName
+}
+
+// Failures lists the failures to retry.
+func (lib *Library) Failures() ([]*Failure, error) {
+	var failures []*Failure
+	if err := lib.db.Order("path").Order("stage").Find(&failures).Error; err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+// WatchHook runs on matched files, e.g. writes NFO and artworks.
+type WatchHook func(ctx context.Context, file *File) error
+
+type watchHook struct {
+	name string
+	fn   WatchHook
+}
+
+// WatchEvent is the result of a watched file, it's logged and posted
+// to the webhook as JSON.
+type WatchEvent struct {
+	// Event is the file status, or "failed" if any hook failed.
+	Event string `json:"event"`
+	File  *File  `json:"file"`
+	Error string `json:"error,omitempty"`
+}
+
+// Watcher watches the library roots, and matches new or changed movie
+// files once they stop changing, then runs the hooks on matched files.
+type Watcher struct {
+	lib   *Library
+	roots []string
+
+	debounce      time.Duration
+	retryInterval time.Duration
+	hooks         []watchHook
+	webhook       string
+	fetcher       *fetch.Fetcher
+	logger        *log.Logger
+}
+
+type WatchOption func(*Watcher)
+
+// WithDebounce sets the time a file must stay unchanged before matched.
+func WithDebounce(d time.Duration) WatchOption {
+	return func(w *Watcher) {
+		w.debounce = d
+	}
+}
+
+// WithRetryInterval sets the interval of retrying failures.
+func WithRetryInterval(d time.Duration) WatchOption {
+	return func(w *Watcher) {
+		w.retryInterval = d
+	}
+}
+
+// WithWatchHook adds a hook run on matched files, the name is used as
+// the failure stage.
+func WithWatchHook(name string, fn WatchHook) WatchOption {
+	return func(w *Watcher) {
+		w.hooks = append(w.hooks, watchHook{name: name, fn: fn})
+	}
+}
+
+// WithWebhook posts the watch events to the url.
+func WithWebhook(url string) WatchOption {
+	return func(w *Watcher) {
+		w.webhook = url
+	}
+}
+
+// WithWatchLogOutput sets the output destination of the watcher logger.
+func WithWatchLogOutput(out io.Writer) WatchOption {
+	return func(w *Watcher) {
+		w.logger = log.New(out, "[LIBRARY]\u0020", log.LstdFlags|log.Llongfile)
+	}
+}
+
+func NewWatcher(lib *Library, roots []string, opts ...WatchOption) *Watcher {
+	w := &Watcher{
+		lib:           lib,
+		roots:         roots,
+		debounce:      DefaultDebounce,
+		retryInterval: DefaultRetryInterval,
+		fetcher:       fetch.Default(&fetch.Config{Timeout: webhookTimeout}),
+		logger:        log.New(os.Stdout, "[LIBRARY]\u0020", log.LstdFlags|log.Llongfile),
+	}
+	// apply options.
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// pendingFile is a changed file waiting to stay unchanged.
+type pendingFile struct {
+	size    int64
+	changed time.Time
+}
+
+// Run watches the roots until the context is done. Existing files are
+// scanned first, so that files changed while not watching are matched.
+func (w *Watcher) Run(ctx context.Context) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+
+	pending := make(map[string]*pendingFile)
+	for i, root := range w.roots {
+		if w.roots[i], err = filepath.Abs(root); err != nil {
+			return err
+		}
+		if err = w.watchDir(fw, w.roots[i], pending); err != nil {
+			return err
+		}
+	}
+
+	// check pending files a few times per debounce period.
+	ticker := time.NewTicker(max(w.debounce/4, 100*time.Millisecond))
+	defer ticker.Stop()
+	retry := time.NewTicker(w.retryInterval)
+	defer retry.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			w.handleEvent(fw, event, pending)
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			w.logger.Printf("watch error: %v", err)
+		case now := <-ticker.C:
+			if ready := w.readyFiles(now, pending); len(ready) > 0 {
+				w.process(ctx, ready)
+			}
+		case <-retry.C:
+			w.retry(ctx)
+		}
+	}
+}
+
+// watchDir watches the dir and its subdirectories, and adds the movie
+// files in them to pending.
+func (w *Watcher) watchDir(fw *fsnotify.Watcher, root string, pending map[string]*pendingFile) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// skip hidden directories, same as Scan.
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return fw.Add(path)
+		}
+		if w.lib.isMovieFile(d.Name()) {
+			w.touch(path, pending)
+		}
+		return nil
+	})
+}
+
+func (w *Watcher) handleEvent(fw *fsnotify.Watcher, event fsnotify.Event, pending map[string]*pendingFile) {
+	switch {
+	case event.Has(fsnotify.Create):
+		info, err := os.Stat(event.Name)
+		if err != nil {
+			return // removed soon after creation.
+		}
+		if info.IsDir() {
+			// directories moved in are never walked by fsnotify.
+			if !strings.HasPrefix(info.Name(), ".") {
+				if err = w.watchDir(fw, event.Name, pending); err != nil {
+					w.logger.Printf("watch %s: %v", event.Name, err)
+				}
+			}
+			return
+		}
+		if w.lib.isMovieFile(info.Name()) {
+			w.touch(event.Name, pending)
+		}
+	case event.Has(fsnotify.Write):
+		if w.lib.isMovieFile(filepath.Base(event.Name)) {
+			w.touch(event.Name, pending)
+		}
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		delete(pending, event.Name)
+		if w.lib.isMovieFile(filepath.Base(event.Name)) {
+			if err := w.lib.forget(event.Name); err != nil {
+				w.logger.Printf("forget %s: %v", event.Name, err)
+			}
+		}
+	}
+}
+
+// touch marks the file as changed now.
+func (w *Watcher) touch(path string, pending map[string]*pendingFile) {
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	pending[path] = &pendingFile{size: size, changed: time.Now()}
+}
+
+// readyFiles removes the files unchanged for the debounce period from
+// pending, and returns them. Files still growing without write events,
+// e.g. on network filesystems, are kept by comparing sizes.
+func (w *Watcher) readyFiles(now time.Time, pending map[string]*pendingFile) []string {
+	var ready []string
+	for path, p := range pending {
+		info, err := os.Stat(path)
+		if err != nil {
+			delete(pending, path)
+			continue
+		}
+		if info.Size() != p.size {
+			p.size, p.changed = info.Size(), now
+			continue
+		}
+		if now.Sub(p.changed) >= w.debounce {
+			ready = append(ready, path)
+			delete(pending, path)
+		}
+	}
+	slices.Sort(ready)
+	return ready
+}
+
+// process matches the files, and runs the hooks on matched files.
+func (w *Watcher) process(ctx context.Context, paths []string) {
+	report, err := w.lib.ScanFiles(ctx, paths...)
+	if err != nil {
+		w.logger.Printf("scan files: %v", err)
+		return
+	}
+	for _, file := range report.Matched {
+		w.clearFailure(file.Path, StageMatch)
+		w.runHooks(ctx, file, w.hooks)
+	}
+	for _, file := range report.Ambiguous {
+		// ambiguous files need manual review, never retried.
+		w.clearFailure(file.Path, StageMatch)
+		w.emit(&WatchEvent{Event: string(file.Status), File: file})
+	}
+	for _, file := range report.Unmatched {
+		w.recordFailure(file.Path, StageMatch, errors.New("no match found"))
+		w.emit(&WatchEvent{Event: string(file.Status), File: file})
+	}
+	for path, msg := range report.Failed {
+		w.recordFailure(path, StageMatch, errors.New(msg))
+		w.emit(&WatchEvent{Event: "failed", File: &File{Path: path}, Error: msg})
+	}
+}
+
+// runHooks runs the hooks on the file, and records the failed ones.
+func (w *Watcher) runHooks(ctx context.Context, file *File, hooks []watchHook) {
+	var errs []error
+	for _, hook := range hooks {
+		if err := hook.fn(ctx, file); err != nil {
+			w.recordFailure(file.Path, hook.name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", hook.name, err))
+			continue
+		}
+		w.clearFailure(file.Path, hook.name)
+	}
+	if err := errors.Join(errs...); err != nil {
+		w.emit(&WatchEvent{Event: "failed", File: file, Error: err.Error()})
+		return
+	}
+	w.emit(&WatchEvent{Event: string(file.Status), File: file})
+}
+
+// retry retries the recorded failures.
+func (w *Watcher) retry(ctx context.Context) {
+	failures, err := w.lib.Failures()
+	if err != nil {
+		w.logger.Printf("list failures: %v", err)
+		return
+	}
+	var (
+		unmatched []string
+		hooks     = make(map[string][]watchHook)
+	)
+	for _, failure := range failures {
+		if _, err = os.Stat(failure.Path); errors.Is(err, os.ErrNotExist) {
+			w.clearFailure(failure.Path, failure.Stage)
+			continue
+		}
+		if failure.Stage == StageMatch {
+			unmatched = append(unmatched, failure.Path)
+			continue
+		}
+		for _, hook := range w.hooks {
+			if hook.name == failure.Stage {
+				hooks[failure.Path] = append(hooks[failure.Path], hook)
+			}
+		}
+	}
+	if len(unmatched) > 0 {
+		w.process(ctx, unmatched)
+	}
+	for path, pathHooks := range hooks {
+		file := &File{}
+		if err = w.lib.db.Limit(1).Find(file, "path = ?", path).Error; err != nil || file.Path == "" {
+			continue
+		}
+		w.runHooks(ctx, file, pathHooks)
+	}
+}
+
+func (w *Watcher) recordFailure(path, stage string, err error) {
+	failure := &Failure{Path: path, Stage: stage}
+	if e := w.lib.db.Limit(1).Find(failure, "path = ? AND stage = ?", path, stage).Error; e != nil {
+		w.logger.Printf("record failure: %v", e)
+		return
+	}
+	failure.Error = err.Error()
+	failure.Attempts++
+	if e := w.lib.db.Save(failure).Error; e != nil {
+		w.logger.Printf("record failure: %v", e)
+	}
+}
+
+func (w *Watcher) clearFailure(path, stage string) {
+	if err := w.lib.db.Delete(&Failure{}, "path = ? AND stage = ?", path, stage).Error; err != nil {
+		w.logger.Printf("clear failure: %v", err)
+	}
+}
+
+// emit logs the event, and posts it to the webhook if set.
+func (w *Watcher) emit(event *WatchEvent) {
+	if event.Error != "" {
+		w.logger.Printf("%s %s: %s", event.Event, event.File.Path, event.Error)
+	} else {
+		w.logger.Printf("%s %s: %s:%s", event.Event, event.File.Path, event.File.Provider, event.File.MovieID)
+	}
+	if w.webhook == "" {
+		return
+	}
+	resp, err := w.fetcher.Post(w.webhook, fetch.WithJSONBody(event),
+		fetch.WithHeader("Content-Type", "application/json"))
+	if err != nil {
+		w.logger.Printf("post webhook: %v", err)
+		return
+	}
+	_ = resp.Body.Close()
+}
+
+// forget deletes the mapping and failures of the removed file.
+func (lib *Library) forget(path string) error {
+	if err := lib.db.Delete(&File{}, "path = ?", path).Error; err != nil {
+		return err
+	}
+	return lib.db.Delete(&Failure{}, "path = ?", path).Error
+}
@@ -0,0 +1,109 @@
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+func TestWatcher(t *testing.T) {
+	searcher := mockSearcher{
+		"ABP-030": {{Provider: "JavBus", ID: "ABP-030", Number: "ABP-030"}},
+	}
+	lib := newTestLibrary(t, searcher)
+
+	var (
+		mu     sync.Mutex
+		events []*WatchEvent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := &WatchEvent{}
+		if json.NewDecoder(r.Body).Decode(event) == nil {
+			mu.Lock()
+			events = append(events, event)
+			mu.Unlock()
+		}
+	}))
+	defer server.Close()
+	eventsOf := func() []*WatchEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(events)
+	}
+
+	var failNFO = true
+	root := t.TempDir()
+	w := NewWatcher(lib, []string{root},
+		WithDebounce(200*time.Millisecond),
+		WithRetryInterval(time.Hour),
+		WithWebhook(server.URL),
+		WithWatchLogOutput(io.Discard),
+		WithWatchHook("nfo", func(_ context.Context, file *File) error {
+			if failNFO {
+				return errors.New("nfo failed")
+			}
+			return WriteNFO(file, []byte("<movie/>"))
+		}),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+	defer func() { cancel(); require.NoError(t, <-done) }()
+
+	// wait for the watcher to start.
+	time.Sleep(100 * time.Millisecond)
+	touch(t, filepath.Join(root, "new", "ABP-030.mp4"))
+	touch(t, filepath.Join(root, "IPX-177.mp4"))
+	require.Eventually(t, func() bool { return len(eventsOf()) == 2 }, 5*time.Second, 50*time.Millisecond)
+
+	byPath := make(map[string]*WatchEvent)
+	for _, event := range eventsOf() {
+		byPath[event.File.Path] = event
+	}
+	if event := byPath[filepath.Join(root, "new", "ABP-030.mp4")]; assert.NotNil(t, event) {
+		assert.Equal(t, "failed", event.Event)
+		assert.Contains(t, event.Error, "nfo failed")
+	}
+	if event := byPath[filepath.Join(root, "IPX-177.mp4")]; assert.NotNil(t, event) {
+		assert.Equal(t, string(StatusUnmatched), event.Event)
+	}
+	failures, err := lib.Failures()
+	require.NoError(t, err)
+	if assert.Len(t, failures, 2) {
+		// ordered by path.
+		assert.Equal(t, StageMatch, failures[0].Stage)
+		assert.Equal(t, 1, failures[0].Attempts)
+		assert.Equal(t, "nfo", failures[1].Stage)
+	}
+
+	// failures are cleared after retried successfully.
+	failNFO = false
+	searcher["IPX-177"] = []*model.MovieSearchResult{{Provider: "JavBus", ID: "IPX-177", Number: "IPX-177"}}
+	w.retry(ctx)
+	failures, err = lib.Failures()
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+	assert.FileExists(t, filepath.Join(root, "new", "ABP-030.nfo"))
+	assert.FileExists(t, filepath.Join(root, "IPX-177.nfo"))
+
+	// removed files are forgotten.
+	require.NoError(t, os.Remove(filepath.Join(root, "IPX-177.mp4")))
+	require.Eventually(t, func() bool {
+		files, err := lib.Files()
+		return err == nil && len(files) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+}
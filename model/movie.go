@@ -8,6 +8,7 @@ import (
 const (
 	MovieMetadataTableName = "movie_metadata"
 	MovieReviewsTableName  = "movie_reviews"
+	MovieActorsTableName   = "movie_actors"
 )
 
 // MovieSearchResult is a subset of MovieInfo.
@@ -68,6 +69,22 @@ func (m *MovieReviewDetail) IsValid() bool {
 	return m.Author != "" && m.Comment != ""
 }
 
+// MovieActor links a movie to an actor name, and to the actor ID if
+// the actor of the same provider is saved. It's derived from the
+// actors of MovieInfo.
+type MovieActor struct {
+	Provider string `json:"provider" gorm:"primaryKey"`
+	MovieID  string `json:"movie_id" gorm:"primaryKey"`
+	Name     string `json:"name" gorm:"primaryKey;index"`
+	ActorID  string `json:"actor_id,omitempty"`
+	// Position is the index in the actors of the movie.
+	Position int `json:"position"`
+}
+
+func (*MovieActor) TableName() string {
+	return MovieActorsTableName
+}
+
 type MovieInfo struct {
 	ID       string `json:"id" gorm:"primaryKey"`
 	Number   string `json:"number"`
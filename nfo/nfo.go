@@ -0,0 +1,247 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+// maxScore is the max score of model.MovieInfo.
+const maxScore = 5
+
+// Movie is the Kodi movie NFO, see https://kodi.wiki/view/NFO_files/Movies.
+type Movie struct {
+	XMLName       xml.Name   `xml:"movie"`
+	Title         string     `xml:"title"`
+	OriginalTitle string     `xml:"originaltitle,omitempty"`
+	SortTitle     string     `xml:"sorttitle,omitempty"`
+	Ratings       *Ratings   `xml:"ratings,omitempty"`
+	Plot          string     `xml:"plot,omitempty"`
+	Outline       string     `xml:"outline,omitempty"`
+	Runtime       int        `xml:"runtime,omitempty"`
+	Thumbs        []Thumb    `xml:"thumb"`
+	Fanart        *Fanart    `xml:"fanart,omitempty"`
+	UniqueIDs     []UniqueID `xml:"uniqueid"`
+	Genres        []string   `xml:"genre"`
+	Set           *Set       `xml:"set,omitempty"`
+	Director      string     `xml:"director,omitempty"`
+	Premiered     string     `xml:"premiered,omitempty"`
+	Year          int        `xml:"year,omitempty"`
+	Studio        string     `xml:"studio,omitempty"`
+	Label         string     `xml:"label,omitempty"`
+	Trailer       string     `xml:"trailer,omitempty"`
+	Website       string     `xml:"website,omitempty"`
+	Actors        []Actor    `xml:"actor"`
+}
+
+type Ratings struct {
+	Ratings []Rating `xml:"rating"`
+}
+
+type Rating struct {
+	Name    string  `xml:"name,attr"`
+	Max     int     `xml:"max,attr"`
+	Default bool    `xml:"default,attr"`
+	Value   float64 `xml:"value"`
+}
+
+type Thumb struct {
+	Aspect string `xml:"aspect,attr,omitempty"`
+	URL    string `xml:",chardata"`
+}
+
+type Fanart struct {
+	Thumbs []Thumb `xml:"thumb"`
+}
+
+type UniqueID struct {
+	Type    string `xml:"type,attr"`
+	Default bool   `xml:"default,attr,omitempty"`
+	Value   string `xml:",chardata"`
+}
+
+type Set struct {
+	Name string `xml:"name"`
+}
+
+type Actor struct {
+	Name  string `xml:"name"`
+	Order int    `xml:"order"`
+	Thumb string `xml:"thumb,omitempty"`
+}
+
+// Images are the image URLs of the movie NFO.
+type Images struct {
+	Poster    string
+	Landscape string
+	Fanart    []string
+}
+
+type config struct {
+	images    Images
+	actors    map[string]*model.ActorInfo
+	actorURL  func(*model.ActorInfo) string
+	uniqueIDs []UniqueID
+	trailer   string
+}
+
+type Option func(*config)
+
+// WithImages sets the image URLs, the movie's own image URLs are used
+// if not set.
+func WithImages(images Images) Option {
+	return func(c *config) {
+		c.images = images
+	}
+}
+
+// WithActors sets the actor infos to include actor thumbs. The thumb is
+// the first image of the actor, or the URL returned by thumbURL if not nil.
+func WithActors(actors []*model.ActorInfo, thumbURL func(*model.ActorInfo) string) Option {
+	return func(c *config) {
+		if c.actors == nil {
+			c.actors = make(map[string]*model.ActorInfo)
+		}
+		for _, actor := range actors {
+			c.actors[actor.Name] = actor
+		}
+		c.actorURL = thumbURL
+	}
+}
+
+// WithUniqueID adds an extra unique ID, e.g., the ID of the same movie
+// from another provider.
+func WithUniqueID(provider, id string) Option {
+	return func(c *config) {
+		c.uniqueIDs = append(c.uniqueIDs, UniqueID{
+			Type:  strings.ToLower(provider),
+			Value: id,
+		})
+	}
+}
+
+// WithTrailer sets the trailer URL, the preview video URL is used if
+// not set.
+func WithTrailer(url string) Option {
+	return func(c *config) {
+		c.trailer = url
+	}
+}
+
+// NewMovie converts movie info into NFO.
+func NewMovie(info *model.MovieInfo, opts ...Option) *Movie {
+	cfg := &config{
+		images: Images{
+			Poster:    info.ThumbURL,
+			Landscape: info.CoverURL,
+			Fanart:    []string{info.CoverURL},
+		},
+		trailer: info.PreviewVideoURL,
+	}
+	if info.BigThumbURL != "" {
+		cfg.images.Poster = info.BigThumbURL
+	}
+	if info.BigCoverURL != "" {
+		cfg.images.Landscape = info.BigCoverURL
+		cfg.images.Fanart = []string{info.BigCoverURL}
+	}
+	// apply options.
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	movie := &Movie{
+		Title:         info.Title,
+		OriginalTitle: info.Title,
+		SortTitle:     info.Number,
+		Plot:          info.Summary,
+		Outline:       info.Summary,
+		Runtime:       info.Runtime,
+		Genres:        info.Genres,
+		Director:      info.Director,
+		Studio:        info.Maker,
+		Label:         info.Label,
+		Trailer:       cfg.trailer,
+		Website:       info.Homepage,
+	}
+	if info.Series != "" {
+		movie.Set = &Set{Name: info.Series}
+	}
+	if date := time.Time(info.ReleaseDate); !date.IsZero() {
+		movie.Premiered = date.Format(time.DateOnly)
+		movie.Year = date.Year()
+	}
+	if info.Score > 0 {
+		movie.Ratings = &Ratings{Ratings: []Rating{{
+			Name:    strings.ToLower(info.Provider),
+			Max:     maxScore,
+			Default: true,
+			Value:   info.Score,
+		}}}
+	}
+
+	// images.
+	if cfg.images.Poster != "" {
+		movie.Thumbs = append(movie.Thumbs, Thumb{Aspect: "poster", URL: cfg.images.Poster})
+	}
+	if cfg.images.Landscape != "" {
+		movie.Thumbs = append(movie.Thumbs, Thumb{Aspect: "landscape", URL: cfg.images.Landscape})
+	}
+	if len(cfg.images.Fanart) > 0 {
+		movie.Fanart = &Fanart{}
+		for _, url := range cfg.images.Fanart {
+			movie.Fanart.Thumbs = append(movie.Fanart.Thumbs, Thumb{URL: url})
+		}
+	}
+
+	// unique ids, the movie's own provider is the default.
+	movie.UniqueIDs = append(movie.UniqueIDs, UniqueID{
+		Type:    strings.ToLower(info.Provider),
+		Default: true,
+		Value:   info.ID,
+	})
+	movie.UniqueIDs = append(movie.UniqueIDs, cfg.uniqueIDs...)
+	if info.Number != "" {
+		movie.UniqueIDs = append(movie.UniqueIDs, UniqueID{Type: "number", Value: info.Number})
+	}
+
+	for i, name := range info.Actors {
+		actor := Actor{Name: name, Order: i}
+		if a, ok := cfg.actors[name]; ok {
+			switch {
+			case cfg.actorURL != nil:
+				actor.Thumb = cfg.actorURL(a)
+			case len(a.Images) > 0:
+				actor.Thumb = a.Images[0]
+			}
+		}
+		movie.Actors = append(movie.Actors, actor)
+	}
+	return movie
+}
+
+// Encode writes the NFO as indented XML with header.
+func (m *Movie) Encode(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Marshal converts movie info into NFO XML.
+func Marshal(info *model.MovieInfo, opts ...Option) ([]byte, error) {
+	sb := &strings.Builder{}
+	if err := NewMovie(info, opts...).Encode(sb); err != nil {
+		return nil, err
+	}
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,65 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+func TestMarshal(t *testing.T) {
+	info := &model.MovieInfo{
+		ID:          "abc00123",
+		Number:      "ABC-123",
+		Title:       "Title & <Test>",
+		Summary:     "Summary",
+		Provider:    "FANZA",
+		Homepage:    "https://example.com/abc00123",
+		Actors:      []string{"Actor A", "Actor B"},
+		ThumbURL:    "https://example.com/thumb.jpg",
+		CoverURL:    "https://example.com/cover.jpg",
+		BigCoverURL: "https://example.com/big_cover.jpg",
+		Maker:       "Studio",
+		Series:      "Series",
+		Genres:      []string{"Drama", "Romance"},
+		Score:       4.5,
+		Runtime:     120,
+		ReleaseDate: datatypes.Date(time.Date(2023, 4, 5, 0, 0, 0, 0, time.UTC)),
+	}
+	data, err := Marshal(info,
+		WithActors([]*model.ActorInfo{
+			{Name: "Actor A", Images: []string{"https://example.com/a.jpg"}},
+		}, nil),
+		WithUniqueID("JavBus", "ABC-123"))
+	require.NoError(t, err)
+
+	movie := &Movie{}
+	require.NoError(t, xml.Unmarshal(data, movie))
+	assert.Equal(t, "Title & <Test>", movie.Title)
+	assert.Equal(t, "ABC-123", movie.SortTitle)
+	assert.Equal(t, "Studio", movie.Studio)
+	assert.Equal(t, "Series", movie.Set.Name)
+	assert.Equal(t, []string{"Drama", "Romance"}, movie.Genres)
+	assert.Equal(t, "2023-04-05", movie.Premiered)
+	assert.Equal(t, 2023, movie.Year)
+	assert.Equal(t, 120, movie.Runtime)
+	assert.Equal(t, []Rating{{Name: "fanza", Max: 5, Default: true, Value: 4.5}}, movie.Ratings.Ratings)
+	assert.Equal(t, []UniqueID{
+		{Type: "fanza", Default: true, Value: "abc00123"},
+		{Type: "javbus", Value: "ABC-123"},
+		{Type: "number", Value: "ABC-123"},
+	}, movie.UniqueIDs)
+	assert.Equal(t, []Thumb{
+		{Aspect: "poster", URL: "https://example.com/thumb.jpg"},
+		{Aspect: "landscape", URL: "https://example.com/big_cover.jpg"},
+	}, movie.Thumbs)
+	assert.Equal(t, []Actor{
+		{Name: "Actor A", Order: 0, Thumb: "https://example.com/a.jpg"},
+		{Name: "Actor B", Order: 1},
+	}, movie.Actors)
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gocolly/colly/v2"
+	"golang.org/x/net/publicsuffix"
 	"golang.org/x/text/language"
 
 	"github.com/metatube-community/metatube-sdk-go/common/fetch"
@@ -28,9 +29,10 @@ import (
 )
 
 var (
-	_ provider.MovieProvider = (*AVBase)(nil)
-	_ provider.MovieSearcher = (*AVBase)(nil)
-	_ provider.Fetcher       = (*AVBase)(nil)
+	_ provider.MovieProvider      = (*AVBase)(nil)
+	_ provider.MovieSearcher      = (*AVBase)(nil)
+	_ provider.Fetcher            = (*AVBase)(nil)
+	_ provider.MediaHostsDeclarer = (*AVBase)(nil)
 )
 
 const (
@@ -71,6 +73,21 @@ func New() *AVBase {
 	}
 }
 
+// MediaHosts returns the hosts of the third-party providers, whose
+// images are referenced by AVBASE.
+func (ab *AVBase) MediaHosts() []string {
+	var hosts []string
+	for _, p := range ab.providers {
+		if domain, err := publicsuffix.EffectiveTLDPlusOne(p.URL().Hostname()); err == nil {
+			hosts = append(hosts, "*."+domain)
+		}
+		if d, ok := p.(provider.MediaHostsDeclarer); ok {
+			hosts = append(hosts, d.MediaHosts()...)
+		}
+	}
+	return hosts
+}
+
 func (ab *AVBase) NormalizeMovieID(id string) string {
 	if !strings.Contains(id, ":") {
 		return strings.ToUpper(id)
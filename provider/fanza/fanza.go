@@ -35,9 +35,10 @@ import (
 )
 
 var (
-	_ provider.MovieProvider = (*FANZA)(nil)
-	_ provider.MovieSearcher = (*FANZA)(nil)
-	_ provider.MovieReviewer = (*FANZA)(nil)
+	_ provider.MovieProvider      = (*FANZA)(nil)
+	_ provider.MovieSearcher      = (*FANZA)(nil)
+	_ provider.MovieReviewer      = (*FANZA)(nil)
+	_ provider.MediaHostsDeclarer = (*FANZA)(nil)
 )
 
 const (
@@ -99,6 +100,10 @@ func (fz *FANZA) SetRequestTimeout(timeout time.Duration) {
 	fz.Scraper.SetRequestTimeout(timeout)
 }
 
+func (fz *FANZA) MediaHosts() []string {
+	return []string{"*.dmm.com"}
+}
+
 func (fz *FANZA) NormalizeMovieID(id string) string {
 	return strings.ToLower(id) /* FANZA uses lowercase ID */
 }
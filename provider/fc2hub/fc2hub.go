@@ -21,9 +21,10 @@ import (
 )
 
 var (
-	_ provider.MovieProvider = (*FC2HUB)(nil)
-	_ provider.MovieSearcher = (*FC2HUB)(nil)
-	_ provider.ConfigSetter  = (*FC2HUB)(nil)
+	_ provider.MovieProvider      = (*FC2HUB)(nil)
+	_ provider.MovieSearcher      = (*FC2HUB)(nil)
+	_ provider.ConfigSetter       = (*FC2HUB)(nil)
+	_ provider.MediaHostsDeclarer = (*FC2HUB)(nil)
 )
 
 const (
@@ -46,6 +47,10 @@ func New() *FC2HUB {
 	return &FC2HUB{Scraper: scraper.NewDefaultScraper(Name, baseURL, Priority, language.Japanese)}
 }
 
+func (fc2hub *FC2HUB) MediaHosts() []string {
+	return []string{"*.fc2.com" /* images from FC2 */}
+}
+
 func (fc2hub *FC2HUB) SetConfig(c provider.Config) error {
 	if c.Has(fc2db.ConfigKeyDatabasePath) {
 		dbPath, _ := c.GetString(fc2db.ConfigKeyDatabasePath)
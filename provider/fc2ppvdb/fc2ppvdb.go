@@ -15,8 +15,9 @@ import (
 )
 
 var (
-	_ provider.MovieProvider = (*FC2PPVDB)(nil)
-	_ provider.ConfigSetter  = (*FC2PPVDB)(nil)
+	_ provider.MovieProvider      = (*FC2PPVDB)(nil)
+	_ provider.ConfigSetter       = (*FC2PPVDB)(nil)
+	_ provider.MediaHostsDeclarer = (*FC2PPVDB)(nil)
 )
 
 const (
@@ -38,6 +39,10 @@ func New() *FC2PPVDB {
 	return &FC2PPVDB{Scraper: scraper.NewDefaultScraper(Name, baseURL, Priority, language.Japanese)}
 }
 
+func (fc2ppvdb *FC2PPVDB) MediaHosts() []string {
+	return []string{"*.fc2.com" /* images from FC2 */}
+}
+
 func (fc2ppvdb *FC2PPVDB) NormalizeMovieID(id string) string {
 	return fc2util.ParseNumber(id)
 }
@@ -19,8 +19,9 @@ import (
 )
 
 var (
-	_ provider.ActorProvider = (*Gfriends)(nil)
-	_ provider.ActorSearcher = (*Gfriends)(nil)
+	_ provider.ActorProvider      = (*Gfriends)(nil)
+	_ provider.ActorSearcher      = (*Gfriends)(nil)
+	_ provider.MediaHostsDeclarer = (*Gfriends)(nil)
 )
 
 const (
@@ -48,6 +49,10 @@ func New() *Gfriends {
 	)}
 }
 
+func (gf *Gfriends) MediaHosts() []string {
+	return []string{"raw.githubusercontent.com"}
+}
+
 func (gf *Gfriends) GetActorInfoByID(id string) (*model.ActorInfo, error) {
 	images, err := _fileTree.query(id)
 	if len(images) == 0 {
@@ -21,8 +21,9 @@ import (
 )
 
 var (
-	_ provider.MovieProvider = (*JAV321)(nil)
-	_ provider.MovieSearcher = (*JAV321)(nil)
+	_ provider.MovieProvider      = (*JAV321)(nil)
+	_ provider.MovieSearcher      = (*JAV321)(nil)
+	_ provider.MediaHostsDeclarer = (*JAV321)(nil)
 )
 
 const (
@@ -48,6 +49,10 @@ func (jav *JAV321) SetRequestTimeout(_ time.Duration) {
 	jav.Scraper.SetRequestTimeout(10 * time.Second)
 }
 
+func (jav *JAV321) MediaHosts() []string {
+	return []string{"*.dmm.co.jp" /* images and preview videos */}
+}
+
 func (jav *JAV321) GetMovieInfoByID(id string) (info *model.MovieInfo, err error) {
 	return jav.GetMovieInfoByURL(fmt.Sprintf(movieURL, id))
 }
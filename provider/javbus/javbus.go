@@ -21,9 +21,10 @@ import (
 )
 
 var (
-	_ provider.MovieProvider = (*JavBus)(nil)
-	_ provider.MovieSearcher = (*JavBus)(nil)
-	_ provider.Fetcher       = (*JavBus)(nil)
+	_ provider.MovieProvider      = (*JavBus)(nil)
+	_ provider.MovieSearcher      = (*JavBus)(nil)
+	_ provider.Fetcher            = (*JavBus)(nil)
+	_ provider.MediaHostsDeclarer = (*JavBus)(nil)
 )
 
 const (
@@ -60,6 +61,10 @@ func New() *JavBus {
 	}
 }
 
+func (bus *JavBus) MediaHosts() []string {
+	return []string{"*.dmm.co.jp" /* preview images */}
+}
+
 func (bus *JavBus) NormalizeMovieID(id string) string {
 	return strings.ToUpper(id)
 }
@@ -19,8 +19,9 @@ import (
 )
 
 var (
-	_ provider.MovieProvider = (*MadouQu)(nil)
-	_ provider.MovieSearcher = (*MadouQu)(nil)
+	_ provider.MovieProvider      = (*MadouQu)(nil)
+	_ provider.MovieSearcher      = (*MadouQu)(nil)
+	_ provider.MediaHostsDeclarer = (*MadouQu)(nil)
 )
 
 const (
@@ -46,6 +47,10 @@ func (mdq *MadouQu) SetRequestTimeout(_ time.Duration) {
 	mdq.Scraper.SetRequestTimeout(10 * time.Second) // force timeout setting.
 }
 
+func (mdq *MadouQu) MediaHosts() []string {
+	return []string{"sp-ao.shortpixel.ai" /* image CDN */}
+}
+
 func (mdq *MadouQu) GetMovieInfoByID(id string) (info *model.MovieInfo, err error) {
 	return mdq.GetMovieInfoByURL(fmt.Sprintf(movieURL, id))
 }
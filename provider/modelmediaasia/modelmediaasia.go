@@ -24,11 +24,12 @@ import (
 )
 
 var (
-	_ provider.ActorProvider = (*ModelMediaAsia)(nil)
-	_ provider.ActorSearcher = (*ModelMediaAsia)(nil)
-	_ provider.MovieProvider = (*ModelMediaAsia)(nil)
-	_ provider.MovieSearcher = (*ModelMediaAsia)(nil)
-	_ provider.Fetcher       = (*ModelMediaAsia)(nil)
+	_ provider.ActorProvider      = (*ModelMediaAsia)(nil)
+	_ provider.ActorSearcher      = (*ModelMediaAsia)(nil)
+	_ provider.MovieProvider      = (*ModelMediaAsia)(nil)
+	_ provider.MovieSearcher      = (*ModelMediaAsia)(nil)
+	_ provider.Fetcher            = (*ModelMediaAsia)(nil)
+	_ provider.MediaHostsDeclarer = (*ModelMediaAsia)(nil)
 )
 
 const (
@@ -57,6 +58,10 @@ func New() *ModelMediaAsia {
 	}
 }
 
+func (mma *ModelMediaAsia) MediaHosts() []string {
+	return []string{"*.bvncmsldo.com" /* API and image CDN */}
+}
+
 // GetMovieInfoByID impls MovieProvider.GetMovieInfoByID.
 func (mma *ModelMediaAsia) GetMovieInfoByID(id string) (info *model.MovieInfo, err error) {
 	info = &model.MovieInfo{
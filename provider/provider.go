@@ -87,6 +87,12 @@ type Fetcher interface {
 	Fetch(url string) (*http.Response, error)
 }
 
+type MediaHostsDeclarer interface {
+	// MediaHosts returns extra hosts (e.g., CDN) that serve media
+	// resources of the provider. A leading "*." matches subdomains.
+	MediaHosts() []string
+}
+
 type RequestTimeoutSetter interface {
 	// SetRequestTimeout sets timeout for HTTP requests.
 	SetRequestTimeout(timeout time.Duration)
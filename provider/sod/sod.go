@@ -21,9 +21,10 @@ import (
 )
 
 var (
-	_ provider.MovieProvider = (*SOD)(nil)
-	_ provider.MovieSearcher = (*SOD)(nil)
-	_ provider.Fetcher       = (*SOD)(nil)
+	_ provider.MovieProvider      = (*SOD)(nil)
+	_ provider.MovieSearcher      = (*SOD)(nil)
+	_ provider.Fetcher            = (*SOD)(nil)
+	_ provider.MediaHostsDeclarer = (*SOD)(nil)
 )
 
 const (
@@ -53,6 +54,10 @@ func New() *SOD {
 	}
 }
 
+func (sod *SOD) MediaHosts() []string {
+	return []string{"dy43ylo5q3vt8.cloudfront.net" /* image CDN */}
+}
+
 func (sod *SOD) NormalizeMovieID(id string) string {
 	return strings.ToUpper(id) /* SOD requires uppercase ID */
 }
@@ -18,8 +18,9 @@ import (
 )
 
 var (
-	_ provider.ActorProvider = (*ThePornDBActor)(nil)
-	_ provider.ActorSearcher = (*ThePornDBActor)(nil)
+	_ provider.ActorProvider      = (*ThePornDBActor)(nil)
+	_ provider.ActorSearcher      = (*ThePornDBActor)(nil)
+	_ provider.MediaHostsDeclarer = (*ThePornDBActor)(nil)
 )
 
 const (
@@ -43,6 +44,10 @@ func NewThePornDBActor() *ThePornDBActor {
 	}
 }
 
+func (s *ThePornDBActor) MediaHosts() []string {
+	return []string{"*.metadataapi.net" /* image CDN */}
+}
+
 func (s *ThePornDBActor) SetConfig(config map[string]string) error {
 	if accessToken, ok := config["ACCESS_TOKEN"]; ok {
 		s.accessToken = accessToken
@@ -18,8 +18,9 @@ import (
 )
 
 var (
-	_ provider.MovieProvider = (*ThePornDBVideo)(nil)
-	_ provider.MovieSearcher = (*ThePornDBVideo)(nil)
+	_ provider.MovieProvider      = (*ThePornDBVideo)(nil)
+	_ provider.MovieSearcher      = (*ThePornDBVideo)(nil)
+	_ provider.MediaHostsDeclarer = (*ThePornDBVideo)(nil)
 )
 
 const (
@@ -70,6 +71,10 @@ func NewThePornDBMovie() *ThePornDBVideo {
 	return new(MovieProviderName, movieBaseURL, moviePageURL, apiGetMovieURL, apiSearchMovieURL)
 }
 
+func (s *ThePornDBVideo) MediaHosts() []string {
+	return []string{"*.metadataapi.net" /* image CDN */}
+}
+
 func (s *ThePornDBVideo) SetConfig(config map[string]string) error {
 	if accessToken, ok := config["ACCESS_TOKEN"]; ok {
 		fmt.Println(s.Name(), "set token")
@@ -10,18 +10,33 @@ import (
 	"github.com/metatube-community/metatube-sdk-go/route/auth"
 )
 
-func authentication(v auth.Validator) gin.HandlerFunc {
+// authentication validates the bearer token, and rejects the request if
+// the token doesn't grant the scope. It's a no-op if auth is disabled.
+func authentication(v auth.Validator, scope auth.Scope) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if v != nil /* auth enabled */ {
 			header := c.GetHeader("Authorization")
 			bearer, token, found := strings.Cut(header, " ")
 
 			hasInvalidHeader := bearer != "Bearer"
-			hasInvalidSecret := !found || !v.Valid(token)
-			if hasInvalidHeader || hasInvalidSecret {
+			if hasInvalidHeader || !found {
 				abortWithError(c, errors.FromCode(http.StatusUnauthorized))
 				return
 			}
+			principal, ok := v.Validate(token)
+			if !ok {
+				abortWithError(c, errors.FromCode(http.StatusUnauthorized))
+				return
+			}
+			if !principal.HasScope(scope) {
+				abortWithError(c, errors.New(http.StatusForbidden,
+					"token is not granted with scope: "+string(scope)))
+				return
+			}
+			if !principal.Allow() {
+				abortWithError(c, errors.FromCode(http.StatusTooManyRequests))
+				return
+			}
 		}
 		c.Next()
 	}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"slices"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Principal is the identity behind a valid token.
+type Principal struct {
+	Name      string
+	Scopes    []Scope
+	ExpiresAt time.Time // zero means never.
+
+	// limiter is nil if rate limit is disabled.
+	limiter *rate.Limiter
+}
+
+func NewPrincipal(name string, scopes ...Scope) *Principal {
+	return &Principal{Name: name, Scopes: scopes}
+}
+
+// HasScope reports whether the principal is granted with scope.
+func (p *Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope) ||
+		slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// Expired reports whether the principal has expired.
+func (p *Principal) Expired() bool {
+	return !p.ExpiresAt.IsZero() && time.Now().After(p.ExpiresAt)
+}
+
+// Allow reports whether a request can happen now under the rate limit.
+func (p *Principal) Allow() bool {
+	return p.limiter == nil || p.limiter.Allow()
+}
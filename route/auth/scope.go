@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"slices"
+)
+
+type Scope string
+
+const (
+	ScopeReadMetadata Scope = "read-metadata"
+	ScopeSearch       Scope = "search"
+	ScopeReviews      Scope = "reviews"
+	ScopeTranslate    Scope = "translate"
+	// ScopeAdmin grants access to all scopes.
+	ScopeAdmin Scope = "admin"
+)
+
+var allScopes = []Scope{
+	ScopeReadMetadata,
+	ScopeSearch,
+	ScopeReviews,
+	ScopeTranslate,
+	ScopeAdmin,
+}
+
+func (s Scope) IsValid() bool {
+	return slices.Contains(allScopes, s)
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureQueryKey = "sig"
+	ExpiresQueryKey   = "exp"
+)
+
+var (
+	ErrSignatureMissing = errors.New("signature is missing")
+	ErrSignatureInvalid = errors.New("signature is invalid")
+	ErrSignatureExpired = errors.New("signature has expired")
+)
+
+// URLSigner signs URL params with HMAC-SHA256 and an expiry.
+type URLSigner struct {
+	key []byte
+}
+
+func NewURLSigner(key []byte) *URLSigner {
+	return &URLSigner{key: key}
+}
+
+// Sign returns the expiry and signature query params of the given params.
+func (s *URLSigner) Sign(params url.Values, expires time.Time) url.Values {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return url.Values{
+		ExpiresQueryKey:   {exp},
+		SignatureQueryKey: {s.signature(params, exp)},
+	}
+}
+
+// Verify verifies the params against the expiry and signature in query.
+func (s *URLSigner) Verify(params url.Values, query url.Values) error {
+	exp, sig := query.Get(ExpiresQueryKey), query.Get(SignatureQueryKey)
+	if exp == "" || sig == "" {
+		return ErrSignatureMissing
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.signature(params, exp))) {
+		return ErrSignatureInvalid
+	}
+	if time.Now().Unix() > unix {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func (s *URLSigner) signature(params url.Values, exp string) string {
+	mac := hmac.New(sha256.New, s.key)
+	// url.Values.Encode sorts params by key.
+	mac.Write([]byte(params.Encode()))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(exp))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLSigner(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"))
+	params := url.Values{
+		"provider": {"FANZA"},
+		"id":       {"ssis00001"},
+		"ratio":    {"0.7"},
+	}
+
+	query := signer.Sign(params, time.Now().Add(time.Hour))
+	assert.NoError(t, signer.Verify(params, query))
+
+	// tampered params.
+	tampered := url.Values{"provider": {"FANZA"}, "id": {"ssis00002"}, "ratio": {"0.7"}}
+	assert.ErrorIs(t, signer.Verify(tampered, query), ErrSignatureInvalid)
+
+	// tampered expiry.
+	query.Set(ExpiresQueryKey, "99999999999")
+	assert.ErrorIs(t, signer.Verify(params, query), ErrSignatureInvalid)
+
+	// wrong key.
+	query = signer.Sign(params, time.Now().Add(time.Hour))
+	assert.ErrorIs(t, NewURLSigner([]byte("other")).Verify(params, query), ErrSignatureInvalid)
+
+	// expired.
+	query = signer.Sign(params, time.Now().Add(-time.Minute))
+	assert.ErrorIs(t, signer.Verify(params, query), ErrSignatureExpired)
+
+	// missing.
+	assert.ErrorIs(t, signer.Verify(params, url.Values{}), ErrSignatureMissing)
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+)
+
+var _ Validator = (*ScopedTokenStore)(nil)
+
+const APITokensTableName = "api_tokens"
+
+// APIToken is a token with scopes, it is also the DB model of tokens.
+type APIToken struct {
+	Token  string         `json:"token" gorm:"primaryKey"`
+	Name   string         `json:"name"`
+	Scopes pq.StringArray `json:"scopes" gorm:"type:text[]"`
+	// ExpiresAt is optional, the token never expires if nil.
+	ExpiresAt *time.Time `json:"expires_at"`
+	// RateLimit is the max requests per minute, 0 means unlimited.
+	RateLimit int `json:"rate_limit"`
+	// Burst is the max requests at once, defaults to RateLimit.
+	Burst int `json:"burst"`
+}
+
+func (*APIToken) TableName() string {
+	return APITokensTableName
+}
+
+func (t *APIToken) toPrincipal() (*Principal, error) {
+	if t.Token == "" {
+		return nil, fmt.Errorf("empty token: %s", t.Name)
+	}
+	p := &Principal{Name: t.Name}
+	for _, s := range t.Scopes {
+		if scope := Scope(s); scope.IsValid() {
+			p.Scopes = append(p.Scopes, scope)
+			continue
+		}
+		return nil, fmt.Errorf("invalid scope of token %s: %q", t.Name, s)
+	}
+	if t.ExpiresAt != nil {
+		p.ExpiresAt = *t.ExpiresAt
+	}
+	if t.RateLimit > 0 {
+		burst := t.Burst
+		if burst <= 0 {
+			burst = t.RateLimit
+		}
+		p.limiter = rate.NewLimiter(rate.Limit(float64(t.RateLimit)/60), burst)
+	}
+	return p, nil
+}
+
+// Loader loads all tokens from a source.
+type Loader func() ([]*APIToken, error)
+
+// FileLoader loads tokens from a JSON file of APIToken array.
+func FileLoader(path string) Loader {
+	return func() ([]*APIToken, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var tokens []*APIToken
+		if err = json.Unmarshal(data, &tokens); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+		return tokens, nil
+	}
+}
+
+// DBLoader loads tokens from the api_tokens table.
+func DBLoader(db *gorm.DB) Loader {
+	return func() ([]*APIToken, error) {
+		var tokens []*APIToken
+		if err := db.Find(&tokens).Error; err != nil {
+			return nil, err
+		}
+		return tokens, nil
+	}
+}
+
+// ScopedTokenStore validates tokens with scopes, expiry and rate
+// limits. Tokens can be hot-reloaded from the loader.
+type ScopedTokenStore struct {
+	mu     sync.RWMutex
+	load   Loader
+	tokens map[string]*APIToken
+	users  map[string]*Principal
+}
+
+func NewScopedTokenStore(load Loader) (*ScopedTokenStore, error) {
+	store := &ScopedTokenStore{load: load}
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reload reloads all tokens from the loader. The rate limit states
+// of the unchanged tokens are preserved.
+func (store *ScopedTokenStore) Reload() error {
+	tokens, err := store.load()
+	if err != nil {
+		return err
+	}
+	store.mu.RLock()
+	prevTokens, prevUsers := store.tokens, store.users
+	store.mu.RUnlock()
+
+	newTokens := make(map[string]*APIToken, len(tokens))
+	newUsers := make(map[string]*Principal, len(tokens))
+	for _, token := range tokens {
+		if prev, ok := prevTokens[token.Token]; ok && equalTokens(prev, token) {
+			newTokens[token.Token], newUsers[token.Token] = prev, prevUsers[token.Token]
+			continue
+		}
+		p, err := token.toPrincipal()
+		if err != nil {
+			return err
+		}
+		newTokens[token.Token], newUsers[token.Token] = token, p
+	}
+
+	store.mu.Lock()
+	store.tokens, store.users = newTokens, newUsers
+	store.mu.Unlock()
+	return nil
+}
+
+// AutoReload reloads tokens periodically until stop is closed.
+func (store *ScopedTokenStore) AutoReload(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := store.Reload(); err != nil {
+				// keep previous tokens on error.
+				log.Printf("[AUTH] Reload tokens error: %v", err)
+			}
+		}
+	}
+}
+
+func (store *ScopedTokenStore) Valid(token string) bool {
+	_, ok := store.Validate(token)
+	return ok
+}
+
+func (store *ScopedTokenStore) Validate(token string) (*Principal, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	p, ok := store.users[token]
+	if !ok || p.Expired() {
+		return nil, false
+	}
+	return p, true
+}
+
+func equalTokens(a, b *APIToken) bool {
+	return a.Name == b.Name &&
+		slices.Equal(a.Scopes, b.Scopes) &&
+		(a.ExpiresAt == nil) == (b.ExpiresAt == nil) &&
+		(a.ExpiresAt == nil || a.ExpiresAt.Equal(*b.ExpiresAt)) &&
+		a.RateLimit == b.RateLimit &&
+		a.Burst == b.Burst
+}
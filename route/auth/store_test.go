@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopedTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokens := func(data string) {
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	}
+	writeTokens(`[
+		{"token": "t-admin", "name": "admin", "scopes": ["admin"]},
+		{"token": "t-reader", "name": "reader", "scopes": ["read-metadata", "search"], "rate_limit": 2},
+		{"token": "t-expired", "name": "old", "scopes": ["admin"], "expires_at": "2000-01-01T00:00:00Z"}
+	]`)
+
+	store, err := NewScopedTokenStore(FileLoader(path))
+	require.NoError(t, err)
+
+	admin, ok := store.Validate("t-admin")
+	require.True(t, ok)
+	assert.True(t, admin.HasScope(ScopeTranslate))
+	assert.True(t, admin.Allow())
+
+	reader, ok := store.Validate("t-reader")
+	require.True(t, ok)
+	assert.True(t, reader.HasScope(ScopeSearch))
+	assert.False(t, reader.HasScope(ScopeAdmin))
+	assert.True(t, reader.Allow())
+	assert.True(t, reader.Allow())
+	assert.False(t, reader.Allow()) // exceeds burst.
+
+	assert.False(t, store.Valid("t-expired"))
+	assert.False(t, store.Valid("unknown"))
+
+	// limiter state survives reloads of unchanged tokens.
+	writeTokens(`[
+		{"token": "t-reader", "name": "reader", "scopes": ["read-metadata", "search"], "rate_limit": 2},
+		{"token": "t-new", "name": "new", "scopes": ["translate"], "expires_at": "` +
+		time.Now().Add(time.Hour).Format(time.RFC3339) + `"}
+	]`)
+	require.NoError(t, store.Reload())
+	assert.False(t, store.Valid("t-admin"))
+	assert.True(t, store.Valid("t-new"))
+	reader, ok = store.Validate("t-reader")
+	require.True(t, ok)
+	assert.False(t, reader.Allow())
+
+	// invalid tokens are rejected, and previous tokens are kept.
+	writeTokens(`[{"token": "t-bad", "scopes": ["root"]}]`)
+	assert.Error(t, store.Reload())
+	assert.True(t, store.Valid("t-new"))
+}
+
+func TestTokenValidate(t *testing.T) {
+	p, ok := Token("secret").Validate("secret")
+	require.True(t, ok)
+	assert.True(t, p.HasScope(ScopeReviews))
+
+	_, ok = NewTokenStore("a", "b").Validate("c")
+	assert.False(t, ok)
+}
@@ -5,12 +5,22 @@ var (
 	_ Validator = (*TokenStore)(nil)
 )
 
+// defaultPrincipal is granted to plain tokens, which have full access.
+var defaultPrincipal = NewPrincipal("default", ScopeAdmin)
+
 type Token string
 
 func (token Token) Valid(t string) bool {
 	return string(token) == t
 }
 
+func (token Token) Validate(t string) (*Principal, bool) {
+	if !token.Valid(t) {
+		return nil, false
+	}
+	return defaultPrincipal, true
+}
+
 type TokenStore map[string]struct{}
 
 func NewTokenStore(tokens ...string) TokenStore {
@@ -33,3 +43,10 @@ func (store TokenStore) Valid(token string) (ok bool) {
 	_, ok = store[token]
 	return
 }
+
+func (store TokenStore) Validate(token string) (*Principal, bool) {
+	if !store.Valid(token) {
+		return nil, false
+	}
+	return defaultPrincipal, true
+}
@@ -1,5 +1,9 @@
 package auth
 
 type Validator interface {
+	// Valid reports whether the token is valid.
 	Valid(string) bool
+
+	// Validate returns the principal of the token if valid.
+	Validate(string) (*Principal, bool)
 }
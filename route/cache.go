@@ -7,6 +7,10 @@ import (
 	cachecontrol "go.eigsys.de/gin-cachecontrol/v2"
 )
 
+// publicCacheMaxAge is the shared cache age of public data, it's planned
+// to cache public data for a long time, especially behind a CDN.
+const publicCacheMaxAge = 180 * 24 * time.Hour
+
 func cachePublicSMaxAge(duration time.Duration) gin.HandlerFunc {
 	return cachecontrol.New(cachecontrol.Config{
 		Public:  true,
@@ -14,6 +18,21 @@ func cachePublicSMaxAge(duration time.Duration) gin.HandlerFunc {
 	})
 }
 
+// capCacheAge lowers the cache age of the public response to at most
+// age, e.g. the remaining lifetime of a signed URL, so that caches never
+// serve it after the signature expires.
+func capCacheAge(c *gin.Context, age time.Duration) {
+	if age >= publicCacheMaxAge {
+		return
+	}
+	age = max(age, 0).Truncate(time.Second)
+	cachecontrol.New(cachecontrol.Config{
+		Public:  true,
+		MaxAge:  cachecontrol.Duration(age),
+		SMaxAge: cachecontrol.Duration(age),
+	})(c)
+}
+
 func cacheNoStore() gin.HandlerFunc {
 	return cachecontrol.New(cachecontrol.Config{
 		// The no-store response directive indicates that any
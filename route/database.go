@@ -1,11 +1,15 @@
 package route
 
 import (
+	goerr "errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/engine/dbengine"
 )
 
 func getDBVersion(app *engine.Engine) gin.HandlerFunc {
@@ -22,3 +26,63 @@ func getDBVersion(app *engine.Engine) gin.HandlerFunc {
 		})
 	}
 }
+
+type dbMoviesQuery struct {
+	Provider       string    `form:"provider"`
+	Actor          string    `form:"actor"`
+	Maker          string    `form:"maker"`
+	Label          string    `form:"label"`
+	Series         string    `form:"series"`
+	Genre          string    `form:"genre"`
+	ReleasedAfter  time.Time `form:"released_after" time_format:"2006-01-02"`
+	ReleasedBefore time.Time `form:"released_before" time_format:"2006-01-02"`
+	MinScore       float64   `form:"min_score"`
+	MaxScore       float64   `form:"max_score"`
+	MinRuntime     int       `form:"min_runtime"`
+	MaxRuntime     int       `form:"max_runtime"`
+	Sort           string    `form:"sort"`
+	Cursor         string    `form:"cursor"`
+	Limit          int       `form:"limit"`
+	// Facets are comma-separated facet fields.
+	Facets string `form:"facets"`
+}
+
+func getDBMovies(app *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := &dbMoviesQuery{}
+		if err := c.ShouldBindQuery(query); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		q := dbengine.MovieQuery{
+			Provider:       query.Provider,
+			Actor:          query.Actor,
+			Maker:          query.Maker,
+			Label:          query.Label,
+			Series:         query.Series,
+			Genre:          query.Genre,
+			ReleasedAfter:  query.ReleasedAfter,
+			ReleasedBefore: query.ReleasedBefore,
+			MinScore:       query.MinScore,
+			MaxScore:       query.MaxScore,
+			MinRuntime:     query.MinRuntime,
+			MaxRuntime:     query.MaxRuntime,
+			Sort:           query.Sort,
+			Cursor:         query.Cursor,
+			Limit:          query.Limit,
+		}
+		if query.Facets != "" {
+			q.Facets = strings.Split(query.Facets, ",")
+		}
+		result, err := app.QueryMovies(q)
+		if err != nil {
+			if goerr.Is(err, dbengine.ErrInvalidQuery) {
+				abortWithStatusMessage(c, http.StatusBadRequest, err)
+				return
+			}
+			abortWithError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, &responseMessage{Data: result})
+	}
+}
@@ -0,0 +1,70 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metatube-community/metatube-sdk-go/common/parser"
+	"github.com/metatube-community/metatube-sdk-go/database"
+	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/engine/dbengine"
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+func TestGetDBMovies(t *testing.T) {
+	db, err := database.Open(&database.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	app := engine.New(db)
+	require.NoError(t, app.DBAutoMigrate(true))
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, db.Create(&model.MovieInfo{
+			ID:          id,
+			Number:      "DB-00" + id,
+			Title:       "Title " + id,
+			Provider:    "DB",
+			Homepage:    "https://example.com/" + id,
+			CoverURL:    "https://example.com/" + id + ".jpg",
+			Maker:       "Maker",
+			Genres:      []string{"Drama"},
+			ReleaseDate: parser.ParseDate("2024-01-0" + id),
+		}).Error)
+	}
+
+	get := func(rawQuery string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/db/movies?"+rawQuery, nil)
+		getDBMovies(app)(c)
+		return w
+	}
+
+	w := get("maker=maker&released_after=2024-01-02&sort=number&limit=1&facets=genre,maker")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data *dbengine.MovieQueryResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.Movies, 1)
+	assert.Equal(t, "2", resp.Data.Movies[0].ID)
+	assert.EqualValues(t, 2, resp.Data.Total)
+	assert.NotEmpty(t, resp.Data.NextCursor)
+	assert.Equal(t, []*dbengine.FacetCount{{Value: "Drama", Count: 2}}, resp.Data.Facets[dbengine.FacetGenre])
+
+	w = get("sort=number&limit=1&released_after=2024-01-02&cursor=" + resp.Data.NextCursor)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	resp.Data = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.Movies, 1)
+	assert.Equal(t, "3", resp.Data.Movies[0].ID)
+	assert.Empty(t, resp.Data.NextCursor)
+
+	for _, rawQuery := range []string{"sort=title", "facets=title", "cursor=invalid", "released_after=today"} {
+		assert.Equal(t, http.StatusBadRequest, get(rawQuery).Code, rawQuery)
+	}
+}
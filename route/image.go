@@ -2,15 +2,19 @@ package route
 
 import (
 	"bytes"
+	"fmt"
 	"image"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/render"
+	"github.com/gin-gonic/gin/binding"
 
 	R "github.com/metatube-community/metatube-sdk-go/constant"
 	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/errors"
 	"github.com/metatube-community/metatube-sdk-go/imageutil"
 	"github.com/metatube-community/metatube-sdk-go/imageutil/badge"
 	mt "github.com/metatube-community/metatube-sdk-go/provider"
@@ -25,10 +29,43 @@ const (
 	primaryImageType imageType = iota
 	thumbImageType
 	backdropImageType
+	previewImageType
+	fanartImageType
 )
 
+func (typ imageType) String() string {
+	switch typ {
+	case primaryImageType:
+		return "primary"
+	case thumbImageType:
+		return "thumb"
+	case backdropImageType:
+		return "backdrop"
+	case previewImageType:
+		return "preview"
+	case fanartImageType:
+		return "fanart"
+	}
+	return "unknown"
+}
+
 type imageUri struct {
 	infoUri // same as info uri
+	// Index is used by preview images only.
+	Index int `uri:"index"`
+}
+
+func imageURIOf(provider, id string) *imageUri {
+	return &imageUri{infoUri: infoUri{Provider: provider, ID: id}}
+}
+
+// path returns the route path of the image.
+func (uri *imageUri) path(typ imageType) string {
+	p := fmt.Sprintf("/v1/images/%s/%s/%s", typ, url.PathEscape(uri.Provider), url.PathEscape(uri.ID))
+	if typ == previewImageType {
+		p += "/" + strconv.Itoa(uri.Index)
+	}
+	return p
 }
 
 type imageQuery struct {
@@ -38,114 +75,321 @@ type imageQuery struct {
 	Auto     bool    `form:"auto"`
 	Badge    string  `form:"badge"`
 	Quality  int     `form:"quality"`
+	Format   string  `form:"format"`
+	Width    int     `form:"w"`
+	Height   int     `form:"h"`
+	Fit      string  `form:"fit"`
+	DPR      float64 `form:"dpr"`
+}
+
+const (
+	// defaultMaxImageSize is the default max width/height of resized images.
+	defaultMaxImageSize = 4096
+	// maxImageDPR is the max device pixel ratio of resized images.
+	maxImageDPR = 4
+)
+
+// resizeImage resizes img to the size requested by query, the output size
+// is scaled by DPR and capped by maxSize, and never exceeds the source size.
+func resizeImage(img image.Image, query *imageQuery, fit imageutil.Fit, maxSize int) image.Image {
+	if query.Width == 0 && query.Height == 0 {
+		return img
+	}
+	if maxSize <= 0 {
+		maxSize = defaultMaxImageSize
+	}
+	dpr := query.DPR
+	if dpr == 0 {
+		dpr = 1
+	}
+	// clamp before scaling to avoid overflows.
+	width := int(math.Round(float64(min(query.Width, maxSize)) * dpr))
+	height := int(math.Round(float64(min(query.Height, maxSize)) * dpr))
+	// scale down proportionally if exceeds max size.
+	if size := max(width, height); size > maxSize {
+		width = width * maxSize / size
+		height = height * maxSize / size
+	}
+	// scale down proportionally if the output would be upscaled.
+	srcW, srcH := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+	scaleW, scaleH := float64(width)/srcW, float64(height)/srcH
+	var scale float64
+	switch {
+	case width == 0:
+		scale = scaleH
+	case height == 0:
+		scale = scaleW
+	case fit == imageutil.FitContain:
+		scale = min(scaleW, scaleH)
+	default:
+		scale = max(scaleW, scaleH)
+	}
+	if scale > 1 {
+		if width > 0 {
+			width = max(1, int(math.Round(float64(width)/scale)))
+		}
+		if height > 0 {
+			height = max(1, int(math.Round(float64(height)/scale)))
+		}
+	}
+	return imageutil.ResizeFit(img, width, height, fit)
+}
+
+// negotiableImageFormats are the output formats negotiated by the Accept
+// header, JPEG comes first to be the default for any other clients. It is
+// encoded with jpegli for smaller files, while format=jpeg still selects
+// the standard library encoder explicitly.
+var negotiableImageFormats = []*imageutil.Format{
+	imageutil.FormatJPEGLI,
+	imageutil.FormatWebP,
+	imageutil.FormatAVIF,
+	imageutil.FormatPNG,
 }
 
-func getImage(app *engine.Engine, typ imageType) gin.HandlerFunc {
-	var ratio float64
+// negotiateImageFormat returns the output format specified by the format
+// query, or negotiated by the Accept header.
+func negotiateImageFormat(c *gin.Context, name string) (*imageutil.Format, bool) {
+	if name != "" {
+		return imageutil.LookupFormat(name)
+	}
+	// the response varies with the Accept header.
+	c.Header("Vary", "Accept")
+
+	offered := make([]string, 0, len(negotiableImageFormats))
+	for _, format := range negotiableImageFormats {
+		offered = append(offered, format.MIMEType)
+	}
+	mimeType := c.NegotiateFormat(offered...)
+	for _, format := range negotiableImageFormats {
+		if format.MIMEType == mimeType {
+			return format, true
+		}
+	}
+	// fallback to JPEG if not acceptable.
+	return imageutil.FormatJPEGLI, true
+}
+
+// ratio returns the crop ratio of the images specified by URLs.
+func (typ imageType) ratio() float64 {
 	switch typ {
 	case primaryImageType:
-		ratio = R.PrimaryImageRatio
-	case thumbImageType:
-		ratio = R.ThumbImageRatio
+		return R.PrimaryImageRatio
+	case thumbImageType, fanartImageType:
+		return R.ThumbImageRatio
 	case backdropImageType:
-		ratio = R.BackdropImageRatio
-	default:
-		panic("invalid image type")
+		return R.BackdropImageRatio
+	case previewImageType:
+		return R.BackdropImageRatio // no cropping
 	}
+	panic("invalid image type")
+}
 
+func parseImageType(s string) (imageType, bool) {
+	for _, typ := range []imageType{
+		primaryImageType,
+		thumbImageType,
+		backdropImageType,
+		previewImageType,
+		fanartImageType,
+	} {
+		if typ.String() == s {
+			return typ, true
+		}
+	}
+	return 0, false
+}
+
+func newImageQuery() *imageQuery {
+	return &imageQuery{
+		Ratio:    -1,
+		Position: -1,
+		Quality:  90,
+	}
+}
+
+// validateImageQuery validates the resize params of query, and returns
+// the fit mode.
+func validateImageQuery(query *imageQuery) (imageutil.Fit, error) {
+	fit, ok := imageutil.ParseFit(query.Fit)
+	if !ok {
+		return fit, errors.New(http.StatusBadRequest, "invalid fit mode")
+	}
+	if query.Width < 0 || query.Height < 0 || query.DPR < 0 || query.DPR > maxImageDPR {
+		return fit, errors.New(http.StatusBadRequest, "invalid image size")
+	}
+	return fit, nil
+}
+
+// imageProviderOf returns the provider of the images, and whether it
+// is an actor provider.
+func imageProviderOf(app *engine.Engine, name string) (mt.Provider, bool, error) {
+	// TODO: how to handle providers that implement
+	//   both actor and movie provider interfaces?
+	switch {
+	case app.IsActorProvider(name) &&
+		name != fc2.Name && name != fc2hub.Name && name != fc2ppvdb.Name:
+		return app.MustGetActorProviderByName(name), true, nil
+	case app.IsMovieProvider(name):
+		return app.MustGetMovieProviderByName(name), false, nil
+	}
+	return nil, false, mt.ErrProviderNotFound
+}
+
+// processImage gets the image of uri, then crops, resizes and badges
+// it as requested by query.
+func processImage(app *engine.Engine, typ imageType, uri *imageUri, query *imageQuery, fit imageutil.Fit, maxSize int) (image.Image, error) {
+	provider, isActorProvider, err := imageProviderOf(app, uri.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var img image.Image
+	if query.URL != "" /* specified URL */ {
+		ratio := query.Ratio
+		// query.Ratio should apply only to the primary images.
+		if typ != primaryImageType || ratio < 0 {
+			ratio = typ.ratio()
+		}
+		img, err = app.GetImageByUntrustedURL(provider, query.URL, ratio, query.Position, query.Auto)
+	} else if isActorProvider /* actor */ {
+		switch typ {
+		case primaryImageType:
+			img, err = app.GetActorPrimaryImage(uri.AsProviderID())
+		default:
+			return nil, errors.New(http.StatusBadRequest, "unsupported image type")
+		}
+	} else /* movie */ {
+		switch typ {
+		case primaryImageType:
+			img, err = app.GetMoviePrimaryImage(uri.AsProviderID(), query.Ratio, query.Position)
+		case thumbImageType:
+			img, err = app.GetMovieThumbImage(uri.AsProviderID())
+		case backdropImageType:
+			img, err = app.GetMovieBackdropImage(uri.AsProviderID())
+		case previewImageType:
+			img, err = app.GetMoviePreviewImage(uri.AsProviderID(), uri.Index)
+		case fanartImageType:
+			img, err = app.GetMovieFanartImage(uri.AsProviderID())
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// resize after cropping, and before badging so
+	// that badges are proportional to output size.
+	img = resizeImage(img, query, fit, maxSize)
+
+	if query.Badge != "" {
+		// badge urls are user-supplied as well.
+		if img, err = badge.BadgeWithFetch(img, query.Badge, func(url string) (*http.Response, error) {
+			return app.FetchUntrusted(url, provider)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
+// GetImage processes the image the same way as the image routes, and
+// returns it encoded. It is meant for in-process clients, e.g. the
+// command-line client. The format defaults to JPEG if not in query.
+func GetImage(app *engine.Engine, typ, provider, id string, index int, query url.Values, opts ...Option) (*imageutil.Format, []byte, error) {
+	cfg := &config{}
+	// apply options.
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	t, ok := parseImageType(typ)
+	if !ok {
+		return nil, nil, errors.New(http.StatusBadRequest, "invalid image type")
+	}
+	q := newImageQuery()
+	if err := binding.MapFormWithTag(q, query, "form"); err != nil {
+		return nil, nil, errors.New(http.StatusBadRequest, err.Error())
+	}
+	format := imageutil.FormatJPEGLI
+	if q.Format != "" {
+		if format, ok = imageutil.LookupFormat(q.Format); !ok {
+			return nil, nil, errors.New(http.StatusBadRequest, "unsupported image format")
+		}
+	}
+	fit, err := validateImageQuery(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	uri := &imageUri{infoUri: infoUri{Provider: provider, ID: id}, Index: index}
+	img, err := processImage(app, t, uri, q, fit, cfg.maxImageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = format.Encode(buf, img, q.Quality); err != nil {
+		return nil, nil, err
+	}
+	return format, buf.Bytes(), nil
+}
+
+func getImage(app *engine.Engine, typ imageType, cfg *config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		uri := &imageUri{}
 		if err := c.ShouldBindUri(uri); err != nil {
 			abortWithStatusMessage(c, http.StatusBadRequest, err)
 			return
 		}
-		query := &imageQuery{
-			Ratio:    -1,
-			Position: -1,
-			Quality:  90,
+		if cfg.signer != nil {
+			if err := verifyImageSignature(c, cfg.signer, typ, uri); err != nil {
+				abortWithStatusMessage(c, http.StatusForbidden, err)
+				return
+			}
 		}
+		query := newImageQuery()
 		if err := c.ShouldBindQuery(query); err != nil {
 			abortWithStatusMessage(c, http.StatusBadRequest, err)
 			return
 		}
-
-		// TODO: how to handle providers that implement
-		//   both actor and movie provider interfaces?
-		var isActorProvider bool
-		switch {
-		case app.IsActorProvider(uri.Provider):
-			if uri.Provider == fc2.Name || uri.Provider == fc2hub.Name || uri.Provider == fc2ppvdb.Name {
-				isActorProvider = false
-			} else {
-				isActorProvider = true
-			}
-		case app.IsMovieProvider(uri.Provider):
-			isActorProvider = false
-		default:
-			abortWithError(c, mt.ErrProviderNotFound)
+		format, ok := negotiateImageFormat(c, query.Format)
+		if !ok {
+			abortWithStatusMessage(c, http.StatusBadRequest, "unsupported image format")
 			return
 		}
-
-		var (
-			img image.Image
-			err error
-		)
-		if query.URL != "" /* specified URL */ {
-			var provider mt.Provider
-			if isActorProvider {
-				provider = app.MustGetActorProviderByName(uri.Provider)
-			} else {
-				provider = app.MustGetMovieProviderByName(uri.Provider)
-			}
-			// query.Ratio should apply only to the primary images.
-			if typ != primaryImageType || query.Ratio < 0 {
-				query.Ratio = ratio
-			}
-			img, err = app.GetImageByURL(provider, query.URL, query.Ratio, query.Position, query.Auto)
-		} else if isActorProvider /* actor */ {
-			switch typ {
-			case primaryImageType:
-				img, err = app.GetActorPrimaryImage(uri.AsProviderID())
-			case thumbImageType, backdropImageType:
-				abortWithStatusMessage(c, http.StatusBadRequest, "unsupported image type")
-				return
-			}
-		} else /* movie */ {
-			switch typ {
-			case primaryImageType:
-				img, err = app.GetMoviePrimaryImage(uri.AsProviderID(), query.Ratio, query.Position)
-			case thumbImageType:
-				img, err = app.GetMovieThumbImage(uri.AsProviderID())
-			case backdropImageType:
-				img, err = app.GetMovieBackdropImage(uri.AsProviderID())
-			}
-		}
+		fit, err := validateImageQuery(query)
 		if err != nil {
 			abortWithError(c, err)
 			return
 		}
+		if _, _, err = imageProviderOf(app, uri.Provider); err != nil {
+			abortWithError(c, err)
+			return
+		}
 
-		if query.Badge != "" {
-			if img, err = badge.Badge(img, query.Badge); err != nil {
-				abortWithError(c, err)
-				return
+		cacheKey := imageCacheKey(typ, uri, query, format)
+		if cfg.imageCache != nil {
+			if buf, ok := cfg.imageCache.Get(cacheKey); ok {
+				if width, height, data, ok := decodeCachedImage(buf); ok {
+					renderImage(c, format, width, height, data)
+					return
+				}
 			}
 		}
 
-		c.Header("X-MetaTube-Image-Width", strconv.Itoa(img.Bounds().Dx()))
-		c.Header("X-MetaTube-Image-Height", strconv.Itoa(img.Bounds().Dy()))
+		img, err := processImage(app, typ, uri, query, fit, cfg.maxImageSize)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
 
 		buf := &bytes.Buffer{}
-		if err = imageutil.EncodeToJPEG(buf, img, query.Quality); err != nil {
+		if err = format.Encode(buf, img, query.Quality); err != nil {
 			panic(err)
 		}
 
-		c.Render(http.StatusOK, render.Reader{
-			ContentType:   jpegImageMIMEType,
-			ContentLength: int64(buf.Len()),
-			Reader:        buf,
-		})
+		width, height := img.Bounds().Dx(), img.Bounds().Dy()
+		if cfg.imageCache != nil {
+			if err = cfg.imageCache.Set(cacheKey, encodeCachedImage(width, height, buf.Bytes())); err != nil {
+				c.Error(err) // log only.
+			}
+		}
+		renderImage(c, format, width, height, buf.Bytes())
 	}
 }
-
-const jpegImageMIMEType = "image/jpeg"
@@ -0,0 +1,72 @@
+package route
+
+import (
+	"image"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/metatube-community/metatube-sdk-go/imageutil"
+)
+
+func TestNegotiateImageFormat(t *testing.T) {
+	for _, unit := range []struct {
+		name   string
+		accept string
+		want   *imageutil.Format
+	}{
+		{"", "", imageutil.FormatJPEGLI},
+		{"", "*/*", imageutil.FormatJPEGLI},
+		{"", "image/avif,image/webp,*/*", imageutil.FormatAVIF},
+		{"", "image/webp,*/*", imageutil.FormatWebP},
+		{"", "text/html", imageutil.FormatJPEGLI},
+		{"jpeg", "image/webp", imageutil.FormatJPEG},
+		{"png", "", imageutil.FormatPNG},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		if unit.accept != "" {
+			c.Request.Header.Set("Accept", unit.accept)
+		}
+		format, ok := negotiateImageFormat(c, unit.name)
+		if assert.True(t, ok) {
+			assert.Equal(t, unit.want, format, unit)
+		}
+	}
+}
+
+func TestResizeImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	for _, unit := range []struct {
+		query  imageQuery
+		fit    imageutil.Fit
+		width  int
+		height int
+	}{
+		{imageQuery{}, imageutil.FitCover, 800, 600},
+		{imageQuery{Width: 400}, imageutil.FitCover, 400, 300},
+		{imageQuery{Width: 200, DPR: 2}, imageutil.FitCover, 400, 300},
+		{imageQuery{Width: 400, Height: 400}, imageutil.FitCover, 400, 400},
+		{imageQuery{Width: 400, Height: 400}, imageutil.FitContain, 400, 300},
+		// never upscale.
+		{imageQuery{Width: 1600}, imageutil.FitCover, 800, 600},
+		{imageQuery{Height: 600, DPR: 4}, imageutil.FitCover, 800, 600},
+		{imageQuery{Width: 1000, Height: 1000}, imageutil.FitCover, 600, 600},
+		{imageQuery{Width: 1000, Height: 1000}, imageutil.FitContain, 800, 600},
+		{imageQuery{Width: 1000, Height: 1000}, imageutil.FitFill, 600, 600},
+		// no overflows.
+		{imageQuery{Width: math.MaxInt, Height: math.MaxInt, DPR: 4}, imageutil.FitCover, 600, 600},
+	} {
+		img := resizeImage(src, &unit.query, unit.fit, 0)
+		assert.Equal(t, unit.width, img.Bounds().Dx(), unit)
+		assert.Equal(t, unit.height, img.Bounds().Dy(), unit)
+	}
+
+	// capped by max size.
+	img := resizeImage(image.NewRGBA(image.Rect(0, 0, 2000, 1000)), &imageQuery{Width: 2000}, imageutil.FitCover, 1000)
+	assert.Equal(t, image.Rect(0, 0, 1000, 500), img.Bounds().Sub(img.Bounds().Min))
+}
@@ -0,0 +1,63 @@
+package route
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+
+	"github.com/metatube-community/metatube-sdk-go/imageutil"
+)
+
+// imageCacheHeaderSize is the size of the width and height
+// header prefixed to the cached image data.
+const imageCacheHeaderSize = 8
+
+// imageCacheKey returns the cache key of the processed image, all params
+// that affect the output must be included.
+func imageCacheKey(typ imageType, uri *imageUri, query *imageQuery, format *imageutil.Format) string {
+	params := url.Values{
+		"url":     {query.URL},
+		"ratio":   {strconv.FormatFloat(query.Ratio, 'f', -1, 64)},
+		"pos":     {strconv.FormatFloat(query.Position, 'f', -1, 64)},
+		"auto":    {strconv.FormatBool(query.Auto)},
+		"badge":   {query.Badge},
+		"quality": {strconv.Itoa(query.Quality)},
+		"format":  {format.Name},
+		"w":       {strconv.Itoa(query.Width)},
+		"h":       {strconv.Itoa(query.Height)},
+		"fit":     {query.Fit},
+		"dpr":     {strconv.FormatFloat(query.DPR, 'f', -1, 64)},
+	}
+	return uri.path(typ) + "?" + params.Encode()
+}
+
+func encodeCachedImage(width, height int, data []byte) []byte {
+	buf := make([]byte, imageCacheHeaderSize, imageCacheHeaderSize+len(data))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(width))
+	binary.BigEndian.PutUint32(buf[4:8], uint32(height))
+	return append(buf, data...)
+}
+
+func decodeCachedImage(buf []byte) (width, height int, data []byte, ok bool) {
+	if len(buf) < imageCacheHeaderSize {
+		return 0, 0, nil, false
+	}
+	width = int(binary.BigEndian.Uint32(buf[0:4]))
+	height = int(binary.BigEndian.Uint32(buf[4:8]))
+	return width, height, buf[imageCacheHeaderSize:], true
+}
+
+func renderImage(c *gin.Context, format *imageutil.Format, width, height int, data []byte) {
+	c.Header("X-MetaTube-Image-Width", strconv.Itoa(width))
+	c.Header("X-MetaTube-Image-Height", strconv.Itoa(height))
+	c.Render(http.StatusOK, render.Reader{
+		ContentType:   format.MIMEType,
+		ContentLength: int64(len(data)),
+		Reader:        bytes.NewReader(data),
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/metatube-community/metatube-sdk-go/engine"
 	"github.com/metatube-community/metatube-sdk-go/engine/providerid"
+	"github.com/metatube-community/metatube-sdk-go/model"
 )
 
 type infoType uint8
@@ -27,9 +28,23 @@ func (uri *infoUri) AsProviderID() providerid.ProviderID {
 
 type infoQuery struct {
 	Lazy bool `form:"lazy"`
+	// ImageURLs includes ready-to-use image URLs in the response,
+	// the URLs are signed if image signing is enabled.
+	ImageURLs bool `form:"image_urls"`
 }
 
-func getInfo(app *engine.Engine, typ infoType) gin.HandlerFunc {
+type actorInfoResponse struct {
+	*model.ActorInfo
+	ImageURLs map[string]string `json:"image_urls"`
+}
+
+type movieInfoResponse struct {
+	*model.MovieInfo
+	ImageURLs        map[string]string `json:"image_urls"`
+	PreviewImageURLs []string          `json:"preview_image_urls"`
+}
+
+func getInfo(app *engine.Engine, typ infoType, cfg *config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		uri := &infoUri{}
 		if err := c.ShouldBindUri(uri); err != nil {
@@ -50,9 +65,40 @@ func getInfo(app *engine.Engine, typ infoType) gin.HandlerFunc {
 		)
 		switch typ {
 		case actorInfoType:
-			info, err = app.GetActorInfoByProviderID(uri.AsProviderID(), query.Lazy)
+			var actor *model.ActorInfo
+			if actor, err = app.GetActorInfoByProviderID(uri.AsProviderID(), query.Lazy); err == nil {
+				info = actor
+				if query.ImageURLs {
+					info = &actorInfoResponse{
+						ActorInfo: actor,
+						ImageURLs: map[string]string{
+							"primary": imageURL(c, cfg, primaryImageType, imageURIOf(actor.Provider, actor.ID), nil),
+						},
+					}
+				}
+			}
 		case movieInfoType:
-			info, err = app.GetMovieInfoByProviderID(uri.AsProviderID(), query.Lazy)
+			var movie *model.MovieInfo
+			if movie, err = app.GetMovieInfoByProviderID(uri.AsProviderID(), query.Lazy); err == nil {
+				info = movie
+				if query.ImageURLs {
+					imageURLs := make(map[string]string)
+					for _, t := range []imageType{primaryImageType, thumbImageType, backdropImageType, fanartImageType} {
+						imageURLs[t.String()] = imageURL(c, cfg, t, imageURIOf(movie.Provider, movie.ID), nil)
+					}
+					previewImageURLs := make([]string, 0, len(movie.PreviewImages))
+					for i := range movie.PreviewImages {
+						uri := imageURIOf(movie.Provider, movie.ID)
+						uri.Index = i
+						previewImageURLs = append(previewImageURLs, imageURL(c, cfg, previewImageType, uri, nil))
+					}
+					info = &movieInfoResponse{
+						MovieInfo:        movie,
+						ImageURLs:        imageURLs,
+						PreviewImageURLs: previewImageURLs,
+					}
+				}
+			}
 		default:
 			panic("invalid info/metadata type")
 		}
@@ -0,0 +1,69 @@
+package route
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/model"
+	"github.com/metatube-community/metatube-sdk-go/nfo"
+)
+
+const xmlMIMEType = "application/xml; charset=utf-8"
+
+func getMovieNFO(app *engine.Engine, cfg *config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uri := &infoUri{}
+		if err := c.ShouldBindUri(uri); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		query := &infoQuery{
+			Lazy: true, // enable lazy by default.
+		}
+		if err := c.ShouldBindQuery(query); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+
+		info, err := app.GetMovieInfoByProviderID(uri.AsProviderID(), query.Lazy)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		// use the proxied images and videos, so that
+		// they are not blocked by hotlink protection.
+		images := nfo.Images{
+			Poster:    imageURL(c, cfg, primaryImageType, imageURIOf(info.Provider, info.ID), nil),
+			Landscape: imageURL(c, cfg, thumbImageType, imageURIOf(info.Provider, info.ID), nil),
+			Fanart:    []string{imageURL(c, cfg, fanartImageType, imageURIOf(info.Provider, info.ID), nil)},
+		}
+		for i := range info.PreviewImages {
+			uri := imageURIOf(info.Provider, info.ID)
+			uri.Index = i
+			images.Fanart = append(images.Fanart, imageURL(c, cfg, previewImageType, uri, nil))
+		}
+		opts := []nfo.Option{nfo.WithImages(images)}
+		if info.PreviewVideoURL != "" || info.PreviewVideoHLSURL != "" {
+			opts = append(opts, nfo.WithTrailer(fmt.Sprintf("%s://%s/v1/videos/preview/%s/%s",
+				requestScheme(c), c.Request.Host, url.PathEscape(info.Provider), url.PathEscape(info.ID))))
+		}
+		// actor infos are optional, only saved ones are used.
+		if actors, err := app.GetActorInfosByNamesFromDB(info.Actors...); err == nil {
+			opts = append(opts, nfo.WithActors(actors, func(actor *model.ActorInfo) string {
+				return imageURL(c, cfg, primaryImageType, imageURIOf(actor.Provider, actor.ID), nil)
+			}))
+		}
+
+		buf := &bytes.Buffer{}
+		if err = nfo.NewMovie(info, opts...).Encode(buf); err != nil {
+			panic(err)
+		}
+		c.Data(http.StatusOK, xmlMIMEType, buf.Bytes())
+	}
+}
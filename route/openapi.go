@@ -0,0 +1,20 @@
+package route
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPISpec is the OpenAPI 3 document of all routes, it's kept in
+// sync with the registered routes by test.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+func getOpenAPI() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+	}
+}
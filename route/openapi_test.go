@@ -0,0 +1,69 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metatube-community/metatube-sdk-go/database"
+	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/route/auth"
+)
+
+var (
+	ginParamRegexp  = regexp.MustCompile(`[:*](\w+)`)
+	specParamRegexp = regexp.MustCompile(`\{\w+}`)
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	db, err := database.Open(&database.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	r := New(engine.New(db), auth.Token("token"))
+
+	var spec struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Scope       string `json:"x-scope"`
+		} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(openAPISpec, &spec))
+
+	registered := make(map[string]bool)
+	for _, route := range r.Routes() {
+		path := ginParamRegexp.ReplaceAllString(route.Path, "{$1}")
+		method := strings.ToLower(route.Method)
+		registered[method+" "+path] = true
+		if assert.Contains(t, spec.Paths, path, "route not documented") {
+			assert.Contains(t, spec.Paths[path], method, "method not documented: %s %s", route.Method, path)
+		}
+	}
+	operationIDs := make(map[string]bool)
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			assert.True(t, registered[method+" "+path], "route not registered: %s %s", method, path)
+			assert.NotEmpty(t, operation.OperationID)
+			assert.False(t, operationIDs[operation.OperationID], "duplicate operationId: %s", operation.OperationID)
+			operationIDs[operation.OperationID] = true
+			if operation.Scope != "" {
+				assert.True(t, auth.Scope(operation.Scope).IsValid(), "invalid scope: %s", operation.Scope)
+			}
+			// documented security must match the authentication.
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(strings.ToUpper(method),
+				specParamRegexp.ReplaceAllString(path, "0"), nil))
+			assert.Equal(t, operation.Scope != "", w.Code == http.StatusUnauthorized,
+				"security mismatch: %s %s", method, path)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, string(openAPISpec), w.Body.String())
+}
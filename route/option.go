@@ -0,0 +1,58 @@
+package route
+
+import (
+	"time"
+
+	"github.com/metatube-community/metatube-sdk-go/common/diskcache"
+	"github.com/metatube-community/metatube-sdk-go/route/auth"
+	"github.com/metatube-community/metatube-sdk-go/translate"
+)
+
+type config struct {
+	glossary *translate.Glossary
+
+	// image signing
+	signer  *auth.URLSigner
+	signTTL time.Duration
+
+	// HLS segment signing
+	segmentSigner *auth.URLSigner
+
+	// max width/height of resized images
+	maxImageSize int
+
+	// cache of processed images
+	imageCache *diskcache.Cache
+}
+
+type Option func(*config)
+
+// WithTranslateGlossary protects glossary terms in all translations.
+func WithTranslateGlossary(g *translate.Glossary) Option {
+	return func(c *config) {
+		c.glossary = g
+	}
+}
+
+// WithImageSigner requires signed URLs for the public image routes,
+// the signed URLs generated by the server expire after ttl.
+func WithImageSigner(signer *auth.URLSigner, ttl time.Duration) Option {
+	return func(c *config) {
+		c.signer = signer
+		c.signTTL = ttl
+	}
+}
+
+// WithMaxImageSize caps the width and height of resized images.
+func WithMaxImageSize(size int) Option {
+	return func(c *config) {
+		c.maxImageSize = size
+	}
+}
+
+// WithImageCache caches the processed images on disk.
+func WithImageCache(cache *diskcache.Cache) Option {
+	return func(c *config) {
+		c.imageCache = cache
+	}
+}
@@ -1,6 +1,7 @@
 package route
 
 import (
+	"crypto/rand"
 	goerr "errors"
 	"fmt"
 	"net/http"
@@ -15,7 +16,23 @@ import (
 	"github.com/metatube-community/metatube-sdk-go/route/auth"
 )
 
-func New(app *engine.Engine, v auth.Validator) *gin.Engine {
+func New(app *engine.Engine, v auth.Validator, opts ...Option) *gin.Engine {
+	cfg := &config{}
+	// apply options.
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	// segment URLs are always signed, otherwise the segment route
+	// would be an open proxy. Fallback to an ephemeral key if image
+	// signing is disabled, the playlists are then only valid until
+	// the server restarts.
+	cfg.segmentSigner = cfg.signer
+	if cfg.segmentSigner == nil {
+		key := make([]byte, 32)
+		_, _ = rand.Read(key)
+		cfg.segmentSigner = auth.NewURLSigner(key)
+	}
+
 	r := gin.New()
 	{
 		// support CORS
@@ -37,43 +54,69 @@ func New(app *engine.Engine, v auth.Validator) *gin.Engine {
 	{
 		system.GET("/modules", getModules())
 		system.GET("/providers", getProviders(app))
+		system.GET("/openapi.json", getOpenAPI())
 	}
 
-	public := r.Group("/v1",
-		// It's planned to cache public data for
-		// a long time, especially behind a CDN.
-		cachePublicSMaxAge(180*24*time.Hour))
+	public := r.Group("/v1", cachePublicSMaxAge(publicCacheMaxAge))
 	{
-		public.GET("/translate", getTranslate())
-
 		images := public.Group("/images")
 		{
-			images.GET("/primary/:provider/:id", getImage(app, primaryImageType))
-			images.GET("/thumb/:provider/:id", getImage(app, thumbImageType))
-			images.GET("/backdrop/:provider/:id", getImage(app, backdropImageType))
+			images.GET("/primary/:provider/:id", getImage(app, primaryImageType, cfg))
+			images.GET("/thumb/:provider/:id", getImage(app, thumbImageType, cfg))
+			images.GET("/backdrop/:provider/:id", getImage(app, backdropImageType, cfg))
+			images.GET("/preview/:provider/:id/:index", getImage(app, previewImageType, cfg))
+			images.GET("/fanart/:provider/:id", getImage(app, fanartImageType, cfg))
 		}
+
+	}
+
+	// playlists contain signed segment URLs that expire,
+	// so they must not be cached as long as images.
+	videos := r.Group("/v1/videos", cachePublicSMaxAge(time.Hour))
+	{
+		videos.GET("/preview/:provider/:id", getPreviewVideo(app, cfg))
+		videos.GET("/preview/:provider/:id/segment", getPreviewVideoSegment(app, cfg))
 	}
 
-	private := r.Group("/v1", authentication(v))
+	private := r.Group("/v1")
 	{
+		// translations are per-token, never cache them publicly.
+		translation := private.Group("/translate", cacheNoStore(), authentication(v, auth.ScopeTranslate))
+		{
+			translation.GET("", getTranslate(cfg.glossary))
+			translation.POST("/batch", postTranslateBatch(cfg.glossary))
+		}
+
 		db := private.Group("/db")
 		{
-			db.GET("/version", getDBVersion(app))
+			db.GET("/version", authentication(v, auth.ScopeAdmin), getDBVersion(app))
+			db.GET("/movies", authentication(v, auth.ScopeReadMetadata), getDBMovies(app))
 		}
 
 		actors := private.Group("/actors")
 		{
-			actors.GET("/:provider/:id", getInfo(app, actorInfoType))
-			actors.GET("/search", getSearch(app, actorSearchType))
+			actors.GET("/:provider/:id", authentication(v, auth.ScopeReadMetadata), getInfo(app, actorInfoType, cfg))
+			actors.GET("/search", authentication(v, auth.ScopeSearch), getSearch(app, actorSearchType))
 		}
 
 		movies := private.Group("/movies")
 		{
-			movies.GET("/:provider/:id", getInfo(app, movieInfoType))
-			movies.GET("/search", getSearch(app, movieSearchType))
+			movies.GET("/:provider/:id", authentication(v, auth.ScopeReadMetadata), getInfo(app, movieInfoType, cfg))
+			movies.GET("/:provider/:id/nfo", authentication(v, auth.ScopeReadMetadata), getMovieNFO(app, cfg))
+			movies.GET("/search", authentication(v, auth.ScopeSearch), getSearch(app, movieSearchType))
+		}
+
+		stash := private.Group("/stash")
+		{
+			stash.GET("/scraper.yml", getStashScraper(app))
+			stash.Match([]string{http.MethodGet, http.MethodPost}, "/scene/fragment",
+				authentication(v, auth.ScopeSearch), getStashSceneByFragment(app, cfg))
+			stash.GET("/scene/url", authentication(v, auth.ScopeReadMetadata), getStashSceneByURL(app, cfg))
+			stash.GET("/performer/name", authentication(v, auth.ScopeSearch), getStashPerformersByName(app, cfg))
+			stash.GET("/performer/url", authentication(v, auth.ScopeReadMetadata), getStashPerformerByURL(app, cfg))
 		}
 
-		reviews := private.Group("/reviews")
+		reviews := private.Group("/reviews", authentication(v, auth.ScopeReviews))
 		{
 			reviews.GET("/:provider/:id", getReview(app))
 		}
@@ -0,0 +1,120 @@
+package route
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/metatube-community/metatube-sdk-go/route/auth"
+)
+
+// defaultSignTTL is the default lifetime of signed image URLs.
+const defaultSignTTL = 7 * 24 * time.Hour
+
+// segmentSignTTL is the lifetime of signed HLS segment URLs, it must
+// be longer than the cache age of the rewritten playlists.
+const segmentSignTTL = 24 * time.Hour
+
+// signedImageQueryKeys are the image query params covered by the
+// signature, i.e. all params that affect the output, so that signed
+// URLs can be neither altered to fetch other URLs (e.g. badge) nor to
+// bypass the image cache. It must be in sync with imageCacheKey.
+var signedImageQueryKeys = []string{
+	"url", "ratio", "pos", "auto", "badge", "quality",
+	"format", "w", "h", "fit", "dpr",
+}
+
+// imageSignParams returns the params to be signed of an image request.
+func imageSignParams(typ imageType, uri *imageUri, query url.Values) url.Values {
+	params := url.Values{
+		"type":     {typ.String()},
+		"provider": {uri.Provider},
+		"id":       {uri.ID},
+	}
+	if typ == previewImageType {
+		params.Set("index", strconv.Itoa(uri.Index))
+	}
+	for _, key := range signedImageQueryKeys {
+		if v := query.Get(key); v != "" {
+			params.Set(key, v)
+		}
+	}
+	return params
+}
+
+// verifyImageSignature verifies the signature of the image request, the
+// response is cached no longer than the signature lives.
+func verifyImageSignature(c *gin.Context, signer *auth.URLSigner, typ imageType, uri *imageUri) error {
+	query := c.Request.URL.Query()
+	if err := signer.Verify(imageSignParams(typ, uri, query), query); err != nil {
+		return err
+	}
+	// the expiry is valid as verified.
+	exp, _ := strconv.ParseInt(query.Get(auth.ExpiresQueryKey), 10, 64)
+	capCacheAge(c, time.Until(time.Unix(exp, 0)))
+	return nil
+}
+
+// imageURL returns the absolute URL of the image route, it is signed
+// if the image signer is configured.
+func imageURL(c *gin.Context, cfg *config, typ imageType, uri *imageUri, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	if cfg.signer != nil {
+		ttl := cfg.signTTL
+		if ttl <= 0 {
+			ttl = defaultSignTTL
+		}
+		// round the expiry up to the hour, so that the URLs stay
+		// the same for a while and can be cached by CDN.
+		expires := time.Now().Add(ttl + time.Hour).Truncate(time.Hour)
+		for k, v := range cfg.signer.Sign(imageSignParams(typ, uri, query), expires) {
+			query[k] = v
+		}
+	}
+	u := fmt.Sprintf("%s://%s%s", requestScheme(c), c.Request.Host, uri.path(typ))
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// segmentSignParams returns the params to be signed of a segment request.
+func segmentSignParams(uri *infoUri, segmentURL string) url.Values {
+	return url.Values{
+		"type":     {"segment"},
+		"provider": {uri.Provider},
+		"id":       {uri.ID},
+		"url":      {segmentURL},
+	}
+}
+
+// signedSegmentQuery returns the signed query of the segment route, so
+// that only segment URLs from the rewritten playlists are proxied.
+func signedSegmentQuery(signer *auth.URLSigner, uri *infoUri, segmentURL string) url.Values {
+	query := url.Values{"url": {segmentURL}}
+	expires := time.Now().Add(segmentSignTTL + time.Hour).Truncate(time.Hour)
+	for k, v := range signer.Sign(segmentSignParams(uri, segmentURL), expires) {
+		query[k] = v
+	}
+	return query
+}
+
+// verifySegmentSignature verifies the signature of the segment request.
+func verifySegmentSignature(c *gin.Context, signer *auth.URLSigner, uri *infoUri, segmentURL string) error {
+	return signer.Verify(segmentSignParams(uri, segmentURL), c.Request.URL.Query())
+}
+
+func requestScheme(c *gin.Context) string {
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		return proto
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -0,0 +1,94 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metatube-community/metatube-sdk-go/imageutil"
+	"github.com/metatube-community/metatube-sdk-go/route/auth"
+)
+
+func TestSignedImageQueryKeys(t *testing.T) {
+	// all params of the cache key must be signed.
+	key := imageCacheKey(primaryImageType, imageURIOf("p", "id"), &imageQuery{}, imageutil.FormatJPEG)
+	_, rawQuery, _ := strings.Cut(key, "?")
+	query, err := url.ParseQuery(rawQuery)
+	require.NoError(t, err)
+	for k := range query {
+		assert.Contains(t, signedImageQueryKeys, k)
+	}
+	assert.Len(t, signedImageQueryKeys, len(query))
+}
+
+func TestImageSignature(t *testing.T) {
+	signer := auth.NewURLSigner([]byte("secret"))
+	uri := imageURIOf("p", "id")
+	query := url.Values{"w": {"100"}}
+	for k, v := range signer.Sign(imageSignParams(thumbImageType, uri, query), time.Now().Add(time.Hour)) {
+		query[k] = v
+	}
+	assert.NoError(t, signer.Verify(imageSignParams(thumbImageType, uri, query), query))
+
+	for _, key := range signedImageQueryKeys {
+		altered := url.Values{}
+		for k, v := range query {
+			altered[k] = v
+		}
+		altered.Set(key, "1")
+		assert.Error(t, signer.Verify(imageSignParams(thumbImageType, uri, altered), altered), key)
+	}
+}
+
+func TestImageSignatureCacheAge(t *testing.T) {
+	signer := auth.NewURLSigner([]byte("secret"))
+	uri := imageURIOf("p", "id")
+
+	for _, unit := range []struct {
+		ttl  time.Duration
+		want string
+	}{
+		{time.Hour, `^public, max-age=359\d, s-maxage=359\d$`},
+		{365 * 24 * time.Hour, `^public, s-maxage=15552000$`},
+	} {
+		query := url.Values{}
+		for k, v := range signer.Sign(imageSignParams(thumbImageType, uri, query), time.Now().Add(unit.ttl)) {
+			query[k] = v
+		}
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil)
+		cachePublicSMaxAge(publicCacheMaxAge)(c)
+		require.NoError(t, verifyImageSignature(c, signer, thumbImageType, uri))
+		assert.Regexp(t, unit.want, w.Header().Get("Cache-Control"))
+	}
+}
+
+func TestSegmentSignature(t *testing.T) {
+	signer := auth.NewURLSigner([]byte("secret"))
+	uri := &infoUri{Provider: "p", ID: "id"}
+	query := signedSegmentQuery(signer, uri, "https://cdn.example.com/1.ts")
+
+	verify := func(uri *infoUri, query url.Values) error {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil)
+		return verifySegmentSignature(c, signer, uri, query.Get("url"))
+	}
+	assert.NoError(t, verify(uri, query))
+
+	altered := url.Values{}
+	for k, v := range query {
+		altered[k] = v
+	}
+	altered.Set("url", "https://cdn.example.com/2.ts")
+	assert.Error(t, verify(uri, altered))
+	assert.Error(t, verify(&infoUri{Provider: "p", ID: "other"}, query))
+	assert.ErrorIs(t, verify(uri, url.Values{"url": {"https://cdn.example.com/1.ts"}}), auth.ErrSignatureMissing)
+}
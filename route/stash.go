@@ -0,0 +1,406 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/metatube-community/metatube-sdk-go/common/comparer"
+	"github.com/metatube-community/metatube-sdk-go/common/number"
+	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/library"
+	"github.com/metatube-community/metatube-sdk-go/model"
+	mt "github.com/metatube-community/metatube-sdk-go/provider"
+)
+
+// Stash scraper contract, see https://docs.stashapp.cc/in-app-manual/scraping/scraperdevelopment/.
+
+type stashName struct {
+	Name string `json:"name"`
+}
+
+type stashScene struct {
+	Title      string      `json:"title"`
+	Code       string      `json:"code,omitempty"`
+	Details    string      `json:"details,omitempty"`
+	Director   string      `json:"director,omitempty"`
+	URL        string      `json:"url,omitempty"`
+	Date       string      `json:"date,omitempty"`
+	Image      string      `json:"image,omitempty"`
+	Studio     *stashName  `json:"studio,omitempty"`
+	Groups     []stashName `json:"groups,omitempty"`
+	Performers []stashName `json:"performers,omitempty"`
+	Tags       []stashName `json:"tags,omitempty"`
+}
+
+type stashPerformer struct {
+	Name         string   `json:"name"`
+	Aliases      string   `json:"aliases,omitempty"`
+	Gender       string   `json:"gender,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	Birthdate    string   `json:"birthdate,omitempty"`
+	Country      string   `json:"country,omitempty"`
+	Height       string   `json:"height,omitempty"`
+	Measurements string   `json:"measurements,omitempty"`
+	CareerLength string   `json:"career_length,omitempty"`
+	Details      string   `json:"details,omitempty"`
+	Images       []string `json:"images,omitempty"`
+}
+
+// stashSceneFragment is the scene fragment sent by Stash, it can be
+// passed either by query or JSON body.
+type stashSceneFragment struct {
+	Title    string `form:"title" json:"title"`
+	Code     string `form:"code" json:"code"`
+	URL      string `form:"url" json:"url"`
+	Filename string `form:"filename" json:"filename"`
+}
+
+// stashTitleMatchThreshold is the min similarity of titles for scenes
+// searched by title.
+const stashTitleMatchThreshold = 0.8
+
+type stashURLQuery struct {
+	URL string `form:"url" json:"url" binding:"required"`
+}
+
+type stashNameQuery struct {
+	Name string `form:"name" json:"name" binding:"required"`
+}
+
+func getStashSceneByFragment(app *engine.Engine, cfg *config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fragment := &stashSceneFragment{}
+		if err := c.ShouldBind(fragment); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		if fragment.URL != "" {
+			if info, err := app.GetMovieInfoByURL(fragment.URL, true); err == nil {
+				c.JSON(http.StatusOK, &responseMessage{Data: toStashScene(c, cfg, info)})
+				return
+			}
+		}
+
+		var (
+			searched bool
+			err      error
+		)
+		// try keywords from the most specific one.
+		for _, keyword := range []struct {
+			text     string
+			isNumber bool
+		}{
+			{fragment.Code, true},
+			{stashFilenameNumber(fragment.Filename), true},
+			{fragment.Title, false},
+		} {
+			if keyword.text == "" {
+				continue
+			}
+			searched = true
+			var results []*model.MovieSearchResult
+			if results, err = app.SearchMovieAll(keyword.text, true); err != nil {
+				continue
+			}
+			result, ok := matchStashScene(keyword.text, keyword.isNumber, results)
+			if !ok {
+				continue
+			}
+			var info *model.MovieInfo
+			if info, err = app.GetMovieInfoByProviderID(
+				imageURIOf(result.Provider, result.ID).AsProviderID(), true); err != nil {
+				continue
+			}
+			c.JSON(http.StatusOK, &responseMessage{Data: toStashScene(c, cfg, info)})
+			return
+		}
+		switch {
+		case !searched:
+			abortWithStatusMessage(c, http.StatusBadRequest, "no keywords in fragment")
+		case err != nil:
+			abortWithError(c, err)
+		default:
+			abortWithError(c, mt.ErrInfoNotFound)
+		}
+	}
+}
+
+// stashFilenameNumber returns the movie number in the scene filename.
+func stashFilenameNumber(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	return number.Trim(path.Base(filename))
+}
+
+// matchStashScene returns the best search result of the keyword, numbers
+// must match confidently, while titles must be similar enough.
+func matchStashScene(keyword string, isNumber bool, results []*model.MovieSearchResult) (*model.MovieSearchResult, bool) {
+	if isNumber {
+		return library.BestMatch(keyword, results, library.DefaultMatchThreshold)
+	}
+	var (
+		best      *model.MovieSearchResult
+		bestScore float64
+	)
+	for _, result := range results {
+		if score := comparer.Compare(keyword, result.Title); score > bestScore {
+			best, bestScore = result, score
+		}
+	}
+	return best, best != nil && bestScore >= stashTitleMatchThreshold
+}
+
+func getStashSceneByURL(app *engine.Engine, cfg *config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := &stashURLQuery{}
+		if err := c.ShouldBind(query); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		info, err := app.GetMovieInfoByURL(query.URL, true)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, &responseMessage{Data: toStashScene(c, cfg, info)})
+	}
+}
+
+func getStashPerformersByName(app *engine.Engine, cfg *config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := &stashNameQuery{}
+		if err := c.ShouldBind(query); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		results, err := app.SearchActorAll(query.Name, true)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		performers := make([]*stashPerformer, 0, len(results))
+		for _, result := range results {
+			performers = append(performers, &stashPerformer{
+				Name:    result.Name,
+				Aliases: strings.Join(result.Aliases, ", "),
+				URL:     result.Homepage,
+			})
+			if len(result.Images) > 0 {
+				performers[len(performers)-1].Images = []string{
+					imageURL(c, cfg, primaryImageType, imageURIOf(result.Provider, result.ID), nil),
+				}
+			}
+		}
+		c.JSON(http.StatusOK, &responseMessage{Data: performers})
+	}
+}
+
+func getStashPerformerByURL(app *engine.Engine, cfg *config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := &stashURLQuery{}
+		if err := c.ShouldBind(query); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		info, err := app.GetActorInfoByURL(query.URL, true)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, &responseMessage{Data: toStashPerformer(c, cfg, info)})
+	}
+}
+
+func toStashScene(c *gin.Context, cfg *config, info *model.MovieInfo) *stashScene {
+	scene := &stashScene{
+		Title:    info.Title,
+		Code:     info.Number,
+		Details:  info.Summary,
+		Director: info.Director,
+		URL:      info.Homepage,
+	}
+	if info.BigCoverURL != "" || info.CoverURL != "" {
+		scene.Image = imageURL(c, cfg, backdropImageType, imageURIOf(info.Provider, info.ID), nil)
+	}
+	if date := time.Time(info.ReleaseDate); !date.IsZero() {
+		scene.Date = date.Format(time.DateOnly)
+	}
+	if info.Maker != "" {
+		scene.Studio = &stashName{Name: info.Maker}
+	}
+	if info.Series != "" {
+		scene.Groups = []stashName{{Name: info.Series}}
+	}
+	for _, actor := range info.Actors {
+		scene.Performers = append(scene.Performers, stashName{Name: actor})
+	}
+	for _, genre := range info.Genres {
+		scene.Tags = append(scene.Tags, stashName{Name: genre})
+	}
+	return scene
+}
+
+func toStashPerformer(c *gin.Context, cfg *config, info *model.ActorInfo) *stashPerformer {
+	performer := &stashPerformer{
+		Name:         info.Name,
+		Aliases:      strings.Join(info.Aliases, ", "),
+		Gender:       "FEMALE", // all actor providers are of actresses.
+		URL:          info.Homepage,
+		Country:      info.Nationality,
+		Measurements: info.Measurements,
+		Details:      info.Summary,
+	}
+	if info.Height > 0 {
+		performer.Height = fmt.Sprint(info.Height)
+	}
+	if date := time.Time(info.Birthday); !date.IsZero() {
+		performer.Birthdate = date.Format(time.DateOnly)
+	}
+	if date := time.Time(info.DebutDate); !date.IsZero() {
+		performer.CareerLength = fmt.Sprintf("%d -", date.Year())
+	}
+	if len(info.Images) > 0 {
+		performer.Images = []string{
+			imageURL(c, cfg, primaryImageType, imageURIOf(info.Provider, info.ID), nil),
+		}
+	}
+	return performer
+}
+
+// stashQueryEscapes are the regex replacements that escape the reserved
+// characters of placeholders, as Stash inserts them into query URLs
+// verbatim. The percent sign must be escaped first.
+var stashQueryEscapes = [][2]string{
+	{"%", "%25"},
+	{"&", "%26"},
+	{"#", "%23"},
+	{`\+`, "%2B"},
+	{" ", "%20"},
+}
+
+// stashQueryURLReplace returns the queryURLReplace YAML block indented
+// by indent, which escapes the placeholders of keys.
+func stashQueryURLReplace(indent int, keys ...string) string {
+	pad := strings.Repeat(" ", indent)
+	sb := &strings.Builder{}
+	sb.WriteString(pad + "queryURLReplace:")
+	for _, key := range keys {
+		fmt.Fprintf(sb, "\n%s  %s:", pad, key)
+		for _, e := range stashQueryEscapes {
+			fmt.Fprintf(sb, "\n%s    - regex: '%s'\n%s      with: '%s'", pad, e[0], pad, e[1])
+		}
+	}
+	return sb.String()
+}
+
+// stashScraperTemplate is the Stash JSON scraper definition of MetaTube.
+var stashScraperTemplate = template.Must(template.New("stash").Funcs(template.FuncMap{
+	"queryURLReplace": stashQueryURLReplace,
+}).Parse(`name: {{.Name}}
+sceneByFragment:
+  action: scrapeJson
+  queryURL: "{{.BaseURL}}/v1/stash/scene/fragment?title={title}&filename={filename}&url={url}"
+{{queryURLReplace 2 "title" "filename" "url"}}
+  scraper: sceneScraper
+sceneByURL:
+  - action: scrapeJson
+    url:
+{{- range .MovieHosts}}
+      - {{.}}
+{{- end}}
+    queryURL: "{{.BaseURL}}/v1/stash/scene/url?url={url}"
+{{queryURLReplace 4 "url"}}
+    scraper: sceneScraper
+performerByName:
+  action: scrapeJson
+  queryURL: "{{.BaseURL}}/v1/stash/performer/name?name={}"
+  scraper: performerSearch
+performerByURL:
+  - action: scrapeJson
+    url:
+{{- range .ActorHosts}}
+      - {{.}}
+{{- end}}
+    queryURL: "{{.BaseURL}}/v1/stash/performer/url?url={url}"
+{{queryURLReplace 4 "url"}}
+    scraper: performerScraper
+jsonScrapers:
+  sceneScraper:
+    scene:
+      Title: data.title
+      Code: data.code
+      Details: data.details
+      Director: data.director
+      URL: data.url
+      Date: data.date
+      Image: data.image
+      Studio:
+        Name: data.studio.name
+      Groups:
+        Name: data.groups.#.name
+      Performers:
+        Name: data.performers.#.name
+      Tags:
+        Name: data.tags.#.name
+  performerSearch:
+    performer:
+      Name: data.#.name
+      URL: data.#.url
+  performerScraper:
+    performer:
+      Name: data.name
+      Aliases: data.aliases
+      Gender: data.gender
+      URL: data.url
+      Birthdate: data.birthdate
+      Country: data.country
+      Height: data.height
+      Measurements: data.measurements
+      CareerLength: data.career_length
+      Details: data.details
+      Image: data.images.0
+# Uncomment to access a token protected server.
+# driver:
+#   headers:
+#     - Key: Authorization
+#       Value: Bearer <token>
+`))
+
+func getStashScraper(app *engine.Engine) gin.HandlerFunc {
+	data := struct {
+		Name       string
+		BaseURL    string
+		MovieHosts []string
+		ActorHosts []string
+	}{
+		Name: app.String(),
+	}
+	for _, provider := range app.GetMovieProviders() {
+		data.MovieHosts = append(data.MovieHosts, provider.URL().Hostname())
+	}
+	for _, provider := range app.GetActorProviders() {
+		data.ActorHosts = append(data.ActorHosts, provider.URL().Hostname())
+	}
+	slices.Sort(data.MovieHosts)
+	slices.Sort(data.ActorHosts)
+	data.MovieHosts = slices.Compact(data.MovieHosts)
+	data.ActorHosts = slices.Compact(data.ActorHosts)
+
+	return func(c *gin.Context) {
+		data := data // copy
+		data.BaseURL = fmt.Sprintf("%s://%s", requestScheme(c), c.Request.Host)
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "application/yaml; charset=utf-8")
+		if err := stashScraperTemplate.Execute(c.Writer, data); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/metatube-community/metatube-sdk-go/database"
+	"github.com/metatube-community/metatube-sdk-go/engine"
+	"github.com/metatube-community/metatube-sdk-go/model"
+)
+
+func TestStashScraper(t *testing.T) {
+	db, err := database.Open(&database.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	getStashScraper(engine.New(db))(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var scraper struct {
+		SceneByFragment struct {
+			QueryURL        string `yaml:"queryURL"`
+			QueryURLReplace map[string][]struct {
+				Regex string `yaml:"regex"`
+				With  string `yaml:"with"`
+			} `yaml:"queryURLReplace"`
+		} `yaml:"sceneByFragment"`
+	}
+	require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &scraper))
+
+	// apply the replacements like Stash does.
+	fragment := scraper.SceneByFragment
+	queryURL := fragment.QueryURL
+	for key, value := range map[string]string{
+		"title":    "A&B #1 100%",
+		"filename": "ABP-030 C+D.mp4",
+		"url":      "https://example.com/?a=1&b=2",
+	} {
+		for _, r := range fragment.QueryURLReplace[key] {
+			value = regexp.MustCompile(r.Regex).ReplaceAllString(value, r.With)
+		}
+		queryURL = strings.ReplaceAll(queryURL, "{"+key+"}", value)
+	}
+	u, err := url.Parse(queryURL)
+	require.NoError(t, err)
+	query := u.Query()
+	assert.Equal(t, "A&B #1 100%", query.Get("title"))
+	assert.Equal(t, "ABP-030 C+D.mp4", query.Get("filename"))
+	assert.Equal(t, "https://example.com/?a=1&b=2", query.Get("url"))
+}
+
+func TestMatchStashScene(t *testing.T) {
+	results := []*model.MovieSearchResult{
+		{Provider: "JavBus", ID: "ABP-031", Number: "ABP-031", Title: "Another Title"},
+		{Provider: "FANZA", ID: "118abp030", Number: "118ABP030", Title: "Some Title"},
+	}
+
+	result, ok := matchStashScene(stashFilenameNumber("/videos/ABP-030-C.mp4"), true, results)
+	if assert.True(t, ok) {
+		assert.Equal(t, "118abp030", result.ID)
+	}
+	_, ok = matchStashScene("IPX-177", true, results)
+	assert.False(t, ok)
+
+	result, ok = matchStashScene("Another Title", false, results)
+	if assert.True(t, ok) {
+		assert.Equal(t, "ABP-031", result.ID)
+	}
+	_, ok = matchStashScene("Unrelated", false, results)
+	assert.False(t, ok)
+}
@@ -1,16 +1,23 @@
 package route
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/schema"
 
+	"github.com/metatube-community/metatube-sdk-go/collection/maps"
 	"github.com/metatube-community/metatube-sdk-go/translate"
 	_ "github.com/metatube-community/metatube-sdk-go/translate/baidu"
 	_ "github.com/metatube-community/metatube-sdk-go/translate/deepl"
 	_ "github.com/metatube-community/metatube-sdk-go/translate/google"
 	_ "github.com/metatube-community/metatube-sdk-go/translate/googlefree"
+	_ "github.com/metatube-community/metatube-sdk-go/translate/libretranslate"
+	_ "github.com/metatube-community/metatube-sdk-go/translate/ollama"
 	_ "github.com/metatube-community/metatube-sdk-go/translate/openai"
 )
 
@@ -22,12 +29,35 @@ type translateQuery struct {
 }
 
 type translateResponse struct {
-	From string `json:"from"`
-	To   string `json:"to"`
-	Text string `json:"translated_text"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Detected string `json:"detected,omitempty"`
+	Text     string `json:"translated_text"`
 }
 
-func getTranslate() gin.HandlerFunc {
+type translateBatchQuery struct {
+	Q      []string `json:"q"`
+	From   string   `json:"from"`
+	To     string   `json:"to"`
+	Engine string   `json:"engine"`
+}
+
+type translateBatchResponse struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Detected []string `json:"detected,omitempty"`
+	Texts    []string `json:"translated_texts"`
+}
+
+const (
+	// maxTranslateBatchSize is the max number of texts per batch.
+	maxTranslateBatchSize = 100
+	// maxTranslateConcurrency limits concurrent requests to
+	// translators without native batch API.
+	maxTranslateConcurrency = 4
+)
+
+func getTranslate(glossary *translate.Glossary) gin.HandlerFunc {
 	decoder := schema.NewDecoder()
 	decoder.SetAliasTag("json")
 	decoder.IgnoreUnknownKeys(true)
@@ -45,9 +75,7 @@ func getTranslate() gin.HandlerFunc {
 			return decoder.Decode(v, c.Request.URL.Query())
 		}
 
-		result, err := translate.
-			New(query.Engine, decode).
-			Translate(query.Q, query.From, query.To)
+		result, detected, err := translateText(glossary, query.Q, query.From, query.To, query.Engine, decode)
 		if err != nil {
 			abortWithError(c, err)
 			return
@@ -55,10 +83,147 @@ func getTranslate() gin.HandlerFunc {
 
 		c.JSON(http.StatusOK, &responseMessage{
 			Data: &translateResponse{
-				From: query.From,
-				To:   query.To,
-				Text: result,
+				From:     query.From,
+				To:       query.To,
+				Detected: detected,
+				Text:     result,
+			},
+		})
+	}
+}
+
+func postTranslateBatch(glossary *translate.Glossary) gin.HandlerFunc {
+	decoder := schema.NewDecoder()
+	decoder.SetAliasTag("json")
+	decoder.IgnoreUnknownKeys(true)
+
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		query := &translateBatchQuery{
+			From: "auto",
+		}
+		if err = json.Unmarshal(body, query); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		switch {
+		case len(query.Q) == 0:
+			abortWithStatusMessage(c, http.StatusBadRequest, "q is required")
+			return
+		case len(query.Q) > maxTranslateBatchSize:
+			abortWithStatusMessage(c, http.StatusBadRequest, "too many texts")
+			return
+		case query.To == "":
+			abortWithStatusMessage(c, http.StatusBadRequest, "to is required")
+			return
+		case query.Engine == "":
+			abortWithStatusMessage(c, http.StatusBadRequest, "engine is required")
+			return
+		}
+
+		// engine configs can be passed either by URL query or JSON body,
+		// and the latter takes precedence.
+		decode := func(v any) error {
+			if err := decoder.Decode(v, c.Request.URL.Query()); err != nil {
+				return err
+			}
+			return json.Unmarshal(body, v)
+		}
+
+		var (
+			detected []string
+			results  = make([]string, len(query.Q))
+			// group texts by source language, so that each
+			// group can be translated within a single batch.
+			groups = maps.NewOrderedMap[string, []int]()
+		)
+		for i, text := range query.Q {
+			source, lang := detectSourceLanguage(text, query.From)
+			if lang != "" && detected == nil {
+				detected = make([]string, len(query.Q))
+			}
+			if lang != "" {
+				detected[i] = lang
+			}
+			if translate.SameLanguage(source, query.To) {
+				results[i] = text // skip translation.
+				continue
+			}
+			indices, _ := groups.Get(source)
+			groups.Set(source, append(indices, i))
+		}
+
+		translator := translate.WithGlossary(translate.New(query.Engine, decode), glossary)
+		for source, indices := range groups.Iterator() {
+			texts := make([]string, 0, len(indices))
+			for _, i := range indices {
+				texts = append(texts, query.Q[i])
+			}
+			translated, err := translate.TranslateAll(translator,
+				texts, source, query.To, maxTranslateConcurrency)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+			for j, i := range indices {
+				results[i] = translated[j]
+			}
+		}
+
+		c.JSON(http.StatusOK, &responseMessage{
+			Data: &translateBatchResponse{
+				From:     query.From,
+				To:       query.To,
+				Detected: detected,
+				Texts:    results,
 			},
 		})
 	}
 }
+
+// Translate translates q the same way as the translate route, and
+// returns the detected source language if any. It is meant for in-process
+// clients, e.g. the command-line client. The engine config is decoded
+// from query.
+func Translate(q, from, to, engine string, query url.Values, opts ...Option) (text, detected string, err error) {
+	cfg := &config{}
+	// apply options.
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	decoder := schema.NewDecoder()
+	decoder.SetAliasTag("json")
+	decoder.IgnoreUnknownKeys(true)
+	return translateText(cfg.glossary, q, from, to, engine, func(v any) error {
+		return decoder.Decode(v, query)
+	})
+}
+
+// translateText translates text with the glossary protected, the
+// translation is skipped if the source language equals to the target.
+func translateText(glossary *translate.Glossary, text, from, to, engine string, decode func(any) error) (result, detected string, err error) {
+	source, detected := detectSourceLanguage(text, from)
+	if translate.SameLanguage(source, to) {
+		return text, detected, nil
+	}
+	result, err = translate.
+		WithGlossary(translate.New(engine, decode), glossary).
+		Translate(text, source, to)
+	return result, detected, err
+}
+
+// detectSourceLanguage detects the language of text if the source
+// language is auto, it returns the detected language if any.
+func detectSourceLanguage(text, from string) (source, detected string) {
+	if from != "" && !strings.EqualFold(from, "auto") {
+		return from, ""
+	}
+	if detected = translate.DetectLanguage(text); detected != "" {
+		return detected, detected
+	}
+	return from, ""
+}
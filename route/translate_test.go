@@ -0,0 +1,41 @@
+package route
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metatube-community/metatube-sdk-go/translate"
+)
+
+// upperTranslator is a fake translator that mangles everything.
+type upperTranslator struct {
+	Suffix string `json:"suffix"`
+}
+
+func (t *upperTranslator) Translate(text, _, _ string) (string, error) {
+	return strings.ToUpper(text) + t.Suffix, nil
+}
+
+func init() {
+	translate.Register(&upperTranslator{})
+}
+
+func TestTranslate(t *testing.T) {
+	// same language, no translation.
+	text, detected, err := Translate("密着誘惑してくるお姉さん", "auto", "ja", "upperTranslator", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "密着誘惑してくるお姉さん", text)
+	assert.Equal(t, translate.LanguageJapanese, detected)
+
+	g := translate.NewGlossary()
+	g.Add("三上悠亜", map[string]string{"en": "Yua Mikami"})
+	text, detected, err = Translate("三上悠亜 debut", "ja", "en", "upperTranslator",
+		url.Values{"suffix": {"!"}}, WithTranslateGlossary(g))
+	require.NoError(t, err)
+	assert.Equal(t, "Yua Mikami DEBUT!", text)
+	assert.Empty(t, detected)
+}
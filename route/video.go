@@ -0,0 +1,210 @@
+package route
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/metatube-community/metatube-sdk-go/common/fetch"
+	"github.com/metatube-community/metatube-sdk-go/common/m3u8"
+	"github.com/metatube-community/metatube-sdk-go/engine"
+	mt "github.com/metatube-community/metatube-sdk-go/provider"
+)
+
+const hlsPlaylistMIMEType = "application/vnd.apple.mpegurl"
+
+// proxiedVideoHeaders are the upstream response headers passed to clients.
+var proxiedVideoHeaders = []string{
+	"Accept-Ranges",
+	"Content-Length",
+	"Content-Range",
+	"Content-Type",
+	"ETag",
+	"Last-Modified",
+}
+
+type videoQuery struct {
+	// HLS prefers HLS stream to MP4 if both are available.
+	HLS bool `form:"hls"`
+}
+
+type videoSegmentQuery struct {
+	URL string `form:"url" binding:"required"`
+}
+
+func getPreviewVideo(app *engine.Engine, cfg *config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uri := &infoUri{}
+		if err := c.ShouldBindUri(uri); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		query := &videoQuery{}
+		if err := c.ShouldBindQuery(query); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		if !app.IsMovieProvider(uri.Provider) {
+			abortWithError(c, mt.ErrProviderNotFound)
+			return
+		}
+
+		info, err := app.GetMovieInfoByProviderID(uri.AsProviderID(), true)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		provider := app.MustGetMovieProviderByName(uri.Provider)
+
+		videoURL, hlsURL := info.PreviewVideoURL, info.PreviewVideoHLSURL
+		// some providers put HLS URLs into the video URL field.
+		if isHLSURL(videoURL) {
+			videoURL, hlsURL = "", videoURL
+		}
+		switch {
+		case hlsURL != "" && (query.HLS || videoURL == ""):
+			proxyHLSPlaylist(c, app, cfg, provider, uri, hlsURL)
+		case videoURL != "":
+			proxyVideo(c, func(opts ...fetch.Option) (*http.Response, error) {
+				return app.FetchWithOptions(videoURL, provider, opts...)
+			})
+		default:
+			abortWithError(c, mt.ErrVideoNotFound)
+		}
+	}
+}
+
+func getPreviewVideoSegment(app *engine.Engine, cfg *config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uri := &infoUri{}
+		if err := c.ShouldBindUri(uri); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		query := &videoSegmentQuery{}
+		if err := c.ShouldBindQuery(query); err != nil {
+			abortWithStatusMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		if err := verifySegmentSignature(c, cfg.segmentSigner, uri, query.URL); err != nil {
+			abortWithStatusMessage(c, http.StatusForbidden, err)
+			return
+		}
+		provider, err := app.GetMovieProviderByName(uri.Provider)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		// signed segment URLs come from the playlists of providers,
+		// their hosts (e.g. CDN) are not necessarily allowed for the
+		// user-supplied URLs, but private addresses are still refused.
+		proxyVideo(c, func(opts ...fetch.Option) (*http.Response, error) {
+			return app.FetchGuarded(query.URL, provider, opts...)
+		})
+	}
+}
+
+// proxyVideo streams the video to client, Range requests are forwarded.
+func proxyVideo(c *gin.Context, fetchFn func(...fetch.Option) (*http.Response, error)) {
+	opts := []fetch.Option{fetch.WithRaiseForStatus(false)}
+	if rangeHeader := c.GetHeader("Range"); rangeHeader != "" {
+		opts = append(opts, fetch.WithHeader("Range", rangeHeader))
+	}
+	resp, err := fetchFn(opts...)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable:
+	default:
+		abortWithStatusMessage(c, http.StatusBadGateway,
+			fmt.Sprintf("upstream status: %s", resp.Status))
+		return
+	}
+
+	for _, key := range proxiedVideoHeaders {
+		if value := resp.Header.Get(key); value != "" {
+			c.Header(key, value)
+		}
+	}
+	c.Status(resp.StatusCode)
+	_, _ = io.Copy(c.Writer, resp.Body)
+}
+
+// proxyHLSPlaylist picks the best variant of the HLS playlist, and
+// rewrites its segment URIs to the signed proxied ones.
+func proxyHLSPlaylist(c *gin.Context, app *engine.Engine, cfg *config, provider mt.Provider, uri *infoUri, playlistURL string) {
+	data, err := fetchAll(app, provider, playlistURL)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	mediaURI, listType, err := m3u8.ParseBestMediaURI(bytes.NewReader(data))
+	if err != nil {
+		abortWithStatusMessage(c, http.StatusBadGateway, err)
+		return
+	}
+	if listType == m3u8.MASTER {
+		if playlistURL, err = resolveURL(playlistURL, mediaURI); err != nil {
+			abortWithStatusMessage(c, http.StatusBadGateway, err)
+			return
+		}
+		if data, err = fetchAll(app, provider, playlistURL); err != nil {
+			abortWithError(c, err)
+			return
+		}
+	}
+
+	segmentPath := fmt.Sprintf("/v1/videos/preview/%s/%s/segment",
+		url.PathEscape(uri.Provider), url.PathEscape(uri.ID))
+	playlist, err := m3u8.RewriteMediaURIs(bytes.NewReader(data), func(segmentURI string) string {
+		abs, err := resolveURL(playlistURL, segmentURI)
+		if err != nil {
+			return segmentURI // keep as is.
+		}
+		return segmentPath + "?" + signedSegmentQuery(cfg.segmentSigner, uri, abs).Encode()
+	})
+	if err != nil {
+		abortWithStatusMessage(c, http.StatusBadGateway, err)
+		return
+	}
+	c.Data(http.StatusOK, hlsPlaylistMIMEType, playlist)
+}
+
+func fetchAll(app *engine.Engine, provider mt.Provider, rawURL string) ([]byte, error) {
+	resp, err := app.Fetch(rawURL, provider)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func resolveURL(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+func isHLSURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(path.Ext(u.Path), ".m3u8")
+}
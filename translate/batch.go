@@ -0,0 +1,78 @@
+package translate
+
+import (
+	"fmt"
+	"sync"
+)
+
+// BatchTranslator is implemented by translators that can translate
+// multiple texts within a single request natively.
+type BatchTranslator interface {
+	BatchTranslate(texts []string, from, to string) ([]string, error)
+}
+
+// TranslateAll translates all texts and returns results in input order.
+// Native batch API is preferred if the translator supports it, otherwise
+// texts are translated concurrently, at most n at a time.
+func TranslateAll(t Translator, texts []string, from, to string, n int) ([]string, error) {
+	if len(texts) == 0 {
+		return []string{}, nil
+	}
+
+	// glossary should be applied outside the batch.
+	if gt, ok := t.(*glossaryTranslator); ok {
+		masked := make([]string, len(texts))
+		restores := make([]func(string) string, len(texts))
+		for i, text := range texts {
+			masked[i], restores[i] = gt.glossary.Mask(text, to)
+		}
+		results, err := TranslateAll(gt.Translator, masked, from, to, n)
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i] = restores[i](results[i])
+		}
+		return results, nil
+	}
+
+	if bt, ok := t.(BatchTranslator); ok {
+		results, err := bt.BatchTranslate(texts, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) != len(texts) {
+			return nil, fmt.Errorf("translate: expected %d results, got %d", len(texts), len(results))
+		}
+		return results, nil
+	}
+
+	if n <= 0 {
+		n = 1
+	}
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, n)
+		errs    = make([]error, len(texts))
+		results = make([]string, len(texts))
+	)
+	for i, text := range texts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, text string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i], errs[i] = t.Translate(text, from, to)
+		}(i, text)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("translate text #%d: %w", i, err)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,79 @@
+package translate
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type slowTranslator struct {
+	running, peak atomic.Int32
+}
+
+func (st *slowTranslator) Translate(text, _, _ string) (string, error) {
+	n := st.running.Add(1)
+	defer st.running.Add(-1)
+	for {
+		if p := st.peak.Load(); n <= p || st.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if text == "" {
+		return "", errors.New("empty text")
+	}
+	return strings.ToUpper(text), nil
+}
+
+type batchTranslator struct{ calls int }
+
+func (bt *batchTranslator) Translate(string, string, string) (string, error) {
+	panic("should not be called")
+}
+
+func (bt *batchTranslator) BatchTranslate(texts []string, _, _ string) ([]string, error) {
+	bt.calls++
+	results := make([]string, len(texts))
+	for i, text := range texts {
+		results[i] = strings.Repeat(text, 2)
+	}
+	return results, nil
+}
+
+func TestTranslateAll(t *testing.T) {
+	texts := []string{"a", "b", "c", "d", "e", "f", "g"}
+
+	st := &slowTranslator{}
+	results, err := TranslateAll(st, texts, "auto", "en", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B", "C", "D", "E", "F", "G"}, results)
+	assert.LessOrEqual(t, st.peak.Load(), int32(3))
+
+	_, err = TranslateAll(st, []string{"a", ""}, "auto", "en", 2)
+	assert.ErrorContains(t, err, "#1")
+
+	bt := &batchTranslator{}
+	results, err = TranslateAll(bt, texts[:3], "auto", "en", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aa", "bb", "cc"}, results)
+	assert.Equal(t, 1, bt.calls)
+
+	results, err = TranslateAll(bt, nil, "auto", "en", 3)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestTranslateAllWithGlossary(t *testing.T) {
+	g := NewGlossary()
+	g.Add("kawaii", map[string]string{"ja": "カワイイ"})
+
+	results, err := TranslateAll(WithGlossary(&batchTranslator{}, g),
+		[]string{"kawaii ", "IPX-001 "}, "auto", "ja", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"カワイイ カワイイ ", "IPX-001 IPX-001 "}, results)
+}
@@ -8,7 +8,10 @@ import (
 	"github.com/metatube-community/metatube-sdk-go/translate"
 )
 
-var _ translate.Translator = (*DeepL)(nil)
+var (
+	_ translate.Translator      = (*DeepL)(nil)
+	_ translate.BatchTranslator = (*DeepL)(nil)
+)
 
 type DeepL struct {
 	APIKey string `json:"deepl-api-key"`
@@ -26,10 +29,38 @@ func (dpl *DeepL) Translate(q, source, target string) (result string, err error)
 		TranslateText(q,
 			parseToSupportedLanguage(target),
 			deeplx.WithSourceLang(
-				parseToSupportedLanguage(source)),
+				parseToSupportedSourceLanguage(source)),
 		)
 }
 
+func (dpl *DeepL) BatchTranslate(q []string, source, target string) ([]string, error) {
+	// Only the official v2 API accepts multiple texts, DeepLX (v1)
+	// endpoints have to be requested one by one. The wrapper hides
+	// this method from TranslateAll to avoid recursion.
+	if dpl.APIUrl != "" && !strings.HasSuffix(strings.TrimRight(dpl.APIUrl, "/"), "/v2") {
+		return translate.TranslateAll(&struct{ translate.Translator }{dpl}, q, source, target, 1)
+	}
+	var opts []deeplx.TranslatorOption
+	if dpl.APIUrl != "" {
+		opts = append(opts, deeplx.WithBaseURL(dpl.APIUrl))
+	}
+	resp, err := deeplx.
+		NewTranslator(dpl.APIKey, append(opts, deeplx.WithVersion(deeplx.VersionV2))...).
+		TranslateTextV2(q,
+			parseToSupportedLanguage(target),
+			deeplx.WithSourceLang(
+				parseToSupportedSourceLanguage(source)),
+		)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]string, 0, len(resp.Translations))
+	for _, translation := range resp.Translations {
+		results = append(results, translation.Text)
+	}
+	return results, nil
+}
+
 func parseToSupportedLanguage(lang string) string {
 	lang = strings.ToUpper(lang)
 	switch lang {
@@ -44,6 +75,13 @@ func parseToSupportedLanguage(lang string) string {
 	}
 }
 
+// parseToSupportedSourceLanguage is like parseToSupportedLanguage,
+// but source language does not accept any variants, e.g. ZH-HANT.
+func parseToSupportedSourceLanguage(lang string) string {
+	lang, _, _ = strings.Cut(parseToSupportedLanguage(lang), "-")
+	return lang
+}
+
 func init() {
 	translate.Register(&DeepL{})
 }
@@ -0,0 +1,108 @@
+package translate
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Languages reported by DetectLanguage.
+const (
+	LanguageJapanese           = "ja"
+	LanguageChineseSimplified  = "zh-CN"
+	LanguageChineseTraditional = "zh-TW"
+	LanguageKorean             = "ko"
+	LanguageEnglish            = "en"
+)
+
+var (
+	// japaneseKanji are shinjitai kanji that are used neither in
+	// Simplified nor Traditional Chinese.
+	japaneseKanji = runeSet("亜悪円桜応覚楽気帰県駅験経続総縄広歳図実戦売読沢浜様涙変恵薬姉")
+
+	// simplifiedHanzi and traditionalHanzi are common characters that
+	// differ between Simplified and Traditional Chinese. Characters
+	// shared with Japanese (e.g. 体, 没, 時, 電) are excluded, so only
+	// Traditional forms that differ from shinjitai are kept.
+	simplifiedHanzi  = runeSet("这个们说时对为过还发经进动问关见长现开让样东门车书马鸟鱼电话语间爱妈无头乐亲认节欢给从变觉脸丝袜饮师")
+	traditionalHanzi = runeSet("這們說國來對會發經關讓樣點當體與媽樂歡沒從變覺臉絲襪內")
+)
+
+func runeSet(s string) map[rune]struct{} {
+	set := make(map[rune]struct{})
+	for _, r := range s {
+		set[r] = struct{}{}
+	}
+	return set
+}
+
+// DetectLanguage identifies the language of text by its scripts and
+// character statistics. Only Japanese, Chinese (Simplified/Traditional),
+// Korean and English are supported, it returns empty string otherwise.
+func DetectLanguage(text string) string {
+	var (
+		kana, hangul, han   int
+		jaHan, scHan, tcHan int
+		ascii, latin        int
+	)
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana) && r != 'ー' && r != '・':
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Han, r):
+			han++
+			if _, ok := japaneseKanji[r]; ok {
+				jaHan++
+			}
+			if _, ok := simplifiedHanzi[r]; ok {
+				scHan++
+			}
+			if _, ok := traditionalHanzi[r]; ok {
+				tcHan++
+			}
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			ascii++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	switch cjk := kana + hangul + han; {
+	case cjk == 0 && ascii == 0:
+		return "" // unknown
+	case hangul > 0 && hangul >= kana+han:
+		return LanguageKorean
+	// Kana rarely appears in Chinese text, so a small amount of
+	// kana or Japanese-only kanji is sufficient for Japanese.
+	case kana*20 >= han && kana > 0, jaHan > 0 && jaHan >= scHan+tcHan:
+		return LanguageJapanese
+	case han > 0:
+		switch {
+		case tcHan > scHan:
+			return LanguageChineseTraditional
+		case scHan > 0:
+			return LanguageChineseSimplified
+		}
+		// Han-only text without any distinctive characters can be
+		// either Chinese or Japanese, e.g. 人妻, leave it to engines.
+		return ""
+	// Other latin languages have lots of accented letters.
+	case latin*20 <= ascii:
+		return LanguageEnglish
+	}
+	return ""
+}
+
+// SameLanguage reports whether the two language codes refer to the same
+// language, e.g. zh-CN and chs, but not zh-CN and zh-TW.
+func SameLanguage(a, b string) bool {
+	normalize := func(lang string) string {
+		if lang = normalizeLanguage(lang); lang == "zh" {
+			return "zh-Hans" // Simplified Chinese by default.
+		}
+		return lang
+	}
+	a, b = normalize(a), normalize(b)
+	return a != "" && strings.EqualFold(a, b)
+}
@@ -0,0 +1,59 @@
+package translate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	for _, unit := range []struct {
+		text, want string
+	}{
+		{"密着誘惑してくるささやき淫語お姉さん", LanguageJapanese},
+		{"エロ配信が担任の先生にバレちゃうなんて！！ 高瀬りな", LanguageJapanese},
+		{"人妻温泉旅行 浜辺の宿", LanguageJapanese},
+		{"这个女孩的故事让人感动", LanguageChineseSimplified},
+		{"這個女孩的故事讓人感動", LanguageChineseTraditional},
+		{"美少女", ""},
+		{"単体作品", ""},
+		{"独占配信", ""},
+		{"企画", ""},
+		{"人妻", ""},
+		{"熟女", ""},
+		{"電車痴漢", ""},
+		{"東京美人", ""},
+		{"長身美脚", ""},
+		{"電話", ""},
+		{"無修正動画", ""},
+		{"從這裡開始", LanguageChineseTraditional},
+		{"사랑하는 아내의 비밀", LanguageKorean},
+		{"Oh yeah! I'm a translator!", LanguageEnglish},
+		{"Über die schöne Müllerin ähnlich Öl", ""},
+		{"SSIS-001 三上悠亜 と一緒に", LanguageJapanese},
+		{"12345 !!!", ""},
+		{"", ""},
+	} {
+		assert.Equal(t, unit.want, DetectLanguage(unit.text), unit.text)
+	}
+}
+
+func TestSameLanguage(t *testing.T) {
+	for _, unit := range []struct {
+		a, b string
+		want bool
+	}{
+		{"zh-CN", "chs", true},
+		{"zh-CN", "zh", true},
+		{"zh_cn", "zh-Hans", true},
+		{"zh-CN", "zh-TW", false},
+		{"zh-TW", "cht", true},
+		{"ja", "jp", true},
+		{"en", "en-US", true},
+		{"ko", "kor", true},
+		{"auto", "auto", false},
+		{"", "", false},
+	} {
+		assert.Equal(t, unit.want, SameLanguage(unit.a, unit.b), "%s vs %s", unit.a, unit.b)
+	}
+}
@@ -0,0 +1,250 @@
+package translate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	// minTermLength is the minimum rune length of a protected term, shorter
+	// terms would match way too much regular text.
+	minTermLength = 2
+	// minNonLatinTermLength is the minimum rune length of non-Latin terms,
+	// which match without word boundaries, e.g. 中出 in 中出し.
+	minNonLatinTermLength = 3
+)
+
+var (
+	// numberPattern matches movie numbers like SSIS-001 or FC2-PPV-1234567.
+	numberPattern = regexp.MustCompile(`(?i)\b[a-z\d]*[a-z]+(?:[-_][a-z\d]+)*[-_]\d{2,}\b`)
+
+	// placeholderPattern matches placeholders in translated text. It is
+	// tolerant of spaces and full-width braces inserted by translators.
+	placeholderPattern = regexp.MustCompile(`[{｛]\s*[{｛]\s*(\d+)\s*[}｝]\s*[}｝]`)
+)
+
+// Term is a protected term of a glossary.
+type Term struct {
+	// Text is the term as it appears in the source text.
+	Text string `json:"text"`
+	// Translations maps target languages to official translations,
+	// the original text is kept if no translation is available.
+	Translations map[string]string `json:"translations,omitempty"`
+}
+
+// Glossary holds a set of protected terms. Terms are masked with
+// placeholders before translation and restored afterward.
+type Glossary struct {
+	mu      sync.RWMutex
+	terms   map[string]*Term // lowercase text:term
+	lengths []int            // distinct rune lengths, descending
+}
+
+func NewGlossary() *Glossary {
+	return &Glossary{terms: make(map[string]*Term)}
+}
+
+// Add adds a term to the glossary. Translations of an existing
+// term are merged, with the new translations taking precedence.
+func (g *Glossary) Add(text string, translations map[string]string) {
+	text = strings.TrimSpace(text)
+	if n := utf8.RuneCountInString(text); n < minTermLength ||
+		(n < minNonLatinTermLength && !isLatin(text)) {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := strings.ToLower(text)
+	term, ok := g.terms[key]
+	if !ok {
+		term = &Term{Text: text}
+		g.terms[key] = term
+		if n := utf8.RuneCountInString(key); !slices.Contains(g.lengths, n) {
+			g.lengths = append(g.lengths, n)
+			slices.SortFunc(g.lengths, func(a, b int) int { return b - a })
+		}
+	}
+	for lang, v := range translations {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		if term.Translations == nil {
+			term.Translations = make(map[string]string)
+		}
+		term.Translations[normalizeLanguage(lang)] = v
+	}
+}
+
+// Load loads terms from a JSON array of Term objects.
+func (g *Glossary) Load(r io.Reader) error {
+	var terms []*Term
+	if err := json.NewDecoder(r).Decode(&terms); err != nil {
+		return fmt.Errorf("decode glossary: %w", err)
+	}
+	for _, term := range terms {
+		g.Add(term.Text, term.Translations)
+	}
+	return nil
+}
+
+// Len returns the number of terms in the glossary.
+func (g *Glossary) Len() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.terms)
+}
+
+// Mask replaces all protected terms and movie numbers in text with
+// placeholders. The returned restore func maps placeholders of the
+// translated text back to the terms in the target language.
+func (g *Glossary) Mask(text, to string) (string, func(string) string) {
+	var replacements []string
+	placeholder := func(s string) string {
+		replacements = append(replacements, s)
+		return "{{" + strconv.Itoa(len(replacements)-1) + "}}"
+	}
+
+	// Mask movie numbers first, they are never translated.
+	text = numberPattern.ReplaceAllStringFunc(text, placeholder)
+
+	g.mu.RLock()
+	if len(g.terms) > 0 {
+		lang := normalizeLanguage(to)
+		runes := []rune(text)
+		sb := &strings.Builder{}
+		for i := 0; i < len(runes); {
+			term, n := g.lookup(runes, i)
+			if term == nil {
+				sb.WriteRune(runes[i])
+				i++
+				continue
+			}
+			sb.WriteString(placeholder(term.translation(lang)))
+			i += n
+		}
+		text = sb.String()
+	}
+	g.mu.RUnlock()
+
+	return text, func(s string) string {
+		return placeholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+			idx, err := strconv.Atoi(placeholderPattern.FindStringSubmatch(m)[1])
+			if err != nil || idx >= len(replacements) {
+				return m // not ours, leave it alone.
+			}
+			return replacements[idx]
+		})
+	}
+}
+
+// lookup finds the longest term starting at runes[i].
+func (g *Glossary) lookup(runes []rune, i int) (*Term, int) {
+	for _, n := range g.lengths {
+		if i+n > len(runes) {
+			continue
+		}
+		term, ok := g.terms[strings.ToLower(string(runes[i:i+n]))]
+		if !ok {
+			continue
+		}
+		// Latin terms must not match in the middle of a word.
+		if isWordRune(runes[i]) && i > 0 && isWordRune(runes[i-1]) {
+			continue
+		}
+		if isWordRune(runes[i+n-1]) && i+n < len(runes) && isWordRune(runes[i+n]) {
+			continue
+		}
+		return term, n
+	}
+	return nil, 0
+}
+
+func (t *Term) translation(lang string) string {
+	if v, ok := t.Translations[lang]; ok {
+		return v
+	}
+	// fallback to base language, e.g. zh-TW -> zh.
+	if base, _, found := strings.Cut(lang, "-"); found {
+		if v, ok := t.Translations[base]; ok {
+			return v
+		}
+	}
+	return t.Text
+}
+
+// isLatin reports whether s consists of ASCII only, whose terms
+// match at word boundaries.
+func isLatin(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return r >= unicode.MaxASCII }) < 0
+}
+
+func isWordRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// normalizeLanguage converts various language codes used by
+// translators to a canonical BCP 47 form, e.g. chs -> zh-Hans.
+func normalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+	switch lang {
+	case "", "auto":
+		return ""
+	case "chs", "zh-cn", "zh-sg":
+		lang = "zh-Hans"
+	case "cht", "zh-tw", "zh-hk", "zh-mo":
+		lang = "zh-Hant"
+	case "jp":
+		lang = "ja"
+	case "kor":
+		lang = "ko"
+	}
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return lang /* fallback to original */
+	}
+	base, _ := tag.Base()
+	if base.String() == "zh" && lang != "zh" {
+		script, _ := tag.Script()
+		return "zh-" + script.String()
+	}
+	return base.String()
+}
+
+var _ Translator = (*glossaryTranslator)(nil)
+
+type glossaryTranslator struct {
+	Translator
+	glossary *Glossary
+}
+
+// WithGlossary wraps a translator to protect glossary terms. It works
+// with any translator, since terms are masked before the translation.
+func WithGlossary(t Translator, g *Glossary) Translator {
+	if g == nil {
+		return t
+	}
+	if _, ok := t.(*errorTranslator); ok {
+		return t
+	}
+	return &glossaryTranslator{Translator: t, glossary: g}
+}
+
+func (gt *glossaryTranslator) Translate(text, from, to string) (string, error) {
+	masked, restore := gt.glossary.Mask(text, to)
+	result, err := gt.Translator.Translate(masked, from, to)
+	if err != nil {
+		return "", err
+	}
+	return restore(result), nil
+}
@@ -0,0 +1,68 @@
+package translate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upperTranslator is a fake translator that mangles everything.
+type upperTranslator struct{}
+
+func (upperTranslator) Translate(text, _, _ string) (string, error) {
+	return strings.ToUpper(text), nil
+}
+
+func TestGlossaryTranslate(t *testing.T) {
+	g := NewGlossary()
+	g.Add("三上悠亜", map[string]string{"en": "Yua Mikami", "zh-CN": "三上悠亚"})
+	g.Add("Mikami", nil)
+	g.Add("亜", nil)  // too short, ignored.
+	g.Add("中出", nil) // non-Latin terms require 3 runes, ignored.
+	g.Add("S1", nil)
+	require.Equal(t, 3, g.Len())
+
+	for _, unit := range []struct {
+		text, to, want string
+	}{
+		{"ssis-001 三上悠亜 debut", "en", "ssis-001 Yua Mikami DEBUT"},
+		{"三上悠亜と亜", "zh-cn", "三上悠亚と亜"},
+		{"三上悠亜", "zh-TW", "三上悠亜"},
+		{"Mikami and Mikamiya", "ja", "Mikami AND MIKAMIYA"},
+		{"S1 中出し", "en", "S1 中出し"},
+		{"s1s2", "en", "S1S2"},
+		{"FC2-PPV-1234567 is fine", "en", "FC2-PPV-1234567 IS FINE"},
+	} {
+		result, err := WithGlossary(upperTranslator{}, g).Translate(unit.text, "auto", unit.to)
+		if assert.NoError(t, err) {
+			assert.Equal(t, unit.want, result)
+		}
+	}
+}
+
+func TestGlossaryRestore(t *testing.T) {
+	g := NewGlossary()
+	g.Add("波多野結衣", nil)
+
+	masked, restore := g.Mask("波多野結衣 ABP-123", "en")
+	assert.Equal(t, "{{1}} {{0}}", masked)
+	// translators may add spaces or full-width braces.
+	assert.Equal(t, "波多野結衣 ABP-123", restore("{ {1} } ｛｛0｝｝"))
+	assert.Equal(t, "{{9}}", restore("{{9}}"))
+}
+
+func TestGlossaryLoad(t *testing.T) {
+	g := NewGlossary()
+	err := g.Load(strings.NewReader(`[
+		{"text": "S1 NO.1 STYLE", "translations": {"zh": "S1"}},
+		{"text": "kawaii*"}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, g.Len())
+
+	masked, restore := g.Mask("by S1 No.1 Style", "zh-Hant")
+	assert.Equal(t, "by {{0}}", masked)
+	assert.Equal(t, "S1", restore("{{0}}"))
+}
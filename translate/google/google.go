@@ -2,6 +2,7 @@ package google
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -12,7 +13,10 @@ import (
 	"github.com/metatube-community/metatube-sdk-go/translate"
 )
 
-var _ translate.Translator = (*Google)(nil)
+var (
+	_ translate.Translator      = (*Google)(nil)
+	_ translate.BatchTranslator = (*Google)(nil)
+)
 
 const googleTranslateAPI = "https://translation.googleapis.com/language/translate/v2"
 
@@ -21,7 +25,15 @@ type Google struct {
 	APIUrl string `json:"google-api-url"`
 }
 
-func (gl *Google) Translate(q, source, target string) (result string, err error) {
+func (gl *Google) Translate(q, source, target string) (string, error) {
+	results, err := gl.BatchTranslate([]string{q}, source, target)
+	if err != nil {
+		return "", err
+	}
+	return results[0], nil
+}
+
+func (gl *Google) BatchTranslate(q []string, source, target string) (results []string, err error) {
 	apiURL := googleTranslateAPI
 	if gl.APIUrl != "" {
 		apiURL = gl.APIUrl
@@ -30,7 +42,7 @@ func (gl *Google) Translate(q, source, target string) (result string, err error)
 	var resp *http.Response
 	if resp, err = fetch.Post(
 		apiURL,
-		fetch.WithJSONBody(map[string]string{
+		fetch.WithJSONBody(map[string]any{
 			"q":      q,
 			"source": parseToSupportedLanguage(source),
 			"target": parseToSupportedLanguage(target),
@@ -56,8 +68,12 @@ func (gl *Google) Translate(q, source, target string) (result string, err error)
 	if err = json.NewDecoder(resp.Body).Decode(&data); err == nil {
 		if data.Error != nil {
 			err = data.Error
+		} else if len(data.Data.Translations) != len(q) {
+			err = fmt.Errorf("expected %d translations, got %d", len(q), len(data.Data.Translations))
 		} else {
-			result = data.Data.Translations[0].TranslatedText
+			for _, translation := range data.Data.Translations {
+				results = append(results, translation.TranslatedText)
+			}
 		}
 	}
 	return
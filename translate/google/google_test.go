@@ -1,8 +1,14 @@
 package google
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGoogleTranslate(t *testing.T) {
@@ -25,3 +31,34 @@ func TestGoogleTranslate(t *testing.T) {
 		t.Log(result)
 	}
 }
+
+func TestGoogleBatchTranslate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Q      []string `json:"q"`
+			Target string   `json:"target"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "key", r.URL.Query().Get("key"))
+		assert.Equal(t, "zh-TW", req.Target)
+
+		var data struct {
+			Data struct {
+				Translations []map[string]string `json:"translations"`
+			} `json:"data"`
+		}
+		for _, q := range req.Q {
+			data.Data.Translations = append(data.Data.Translations,
+				map[string]string{"translatedText": "<" + q + ">"})
+		}
+		json.NewEncoder(w).Encode(data)
+	}))
+	defer srv.Close()
+
+	results, err := (&Google{
+		APIKey: "key",
+		APIUrl: srv.URL,
+	}).BatchTranslate([]string{"a", "b", "c"}, "", "zh-TW")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"<a>", "<b>", "<c>"}, results)
+}
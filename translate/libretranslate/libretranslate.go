@@ -0,0 +1,111 @@
+package libretranslate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/metatube-community/metatube-sdk-go/common/fetch"
+	"github.com/metatube-community/metatube-sdk-go/translate"
+)
+
+var (
+	_ translate.Translator      = (*LibreTranslate)(nil)
+	_ translate.BatchTranslator = (*LibreTranslate)(nil)
+)
+
+const defaultLibreTranslateAPI = "http://localhost:5000"
+
+// LibreTranslate is a translator for self-hosted LibreTranslate servers.
+type LibreTranslate struct {
+	APIKey string `json:"libretranslate-api-key"`
+	APIUrl string `json:"libretranslate-api-url"`
+}
+
+func (lt *LibreTranslate) Translate(q, source, target string) (string, error) {
+	var result string
+	return result, lt.translate(q, source, target, &result)
+}
+
+func (lt *LibreTranslate) BatchTranslate(q []string, source, target string) ([]string, error) {
+	var results []string
+	if err := lt.translate(q, source, target, &results); err != nil {
+		return nil, err
+	}
+	if len(results) != len(q) {
+		return nil, fmt.Errorf("expected %d translations, got %d", len(q), len(results))
+	}
+	return results, nil
+}
+
+// translate requests the translation of q, which is either
+// a string or a slice of strings, and decodes it into v.
+func (lt *LibreTranslate) translate(q any, source, target string, v any) (err error) {
+	apiURL := defaultLibreTranslateAPI
+	if lt.APIUrl != "" {
+		apiURL = lt.APIUrl
+	}
+	if apiURL, err = url.JoinPath(apiURL, "translate"); err != nil {
+		return
+	}
+
+	body := map[string]any{
+		"q":      q,
+		"source": parseToSupportedLanguage(source),
+		"target": parseToSupportedLanguage(target),
+		"format": "text",
+	}
+	if lt.APIKey != "" {
+		body["api_key"] = lt.APIKey
+	}
+
+	var resp *http.Response
+	if resp, err = fetch.Post(
+		apiURL,
+		fetch.WithJSONBody(body),
+		fetch.WithRaiseForStatus(false),
+		fetch.WithHeader("Content-Type", "application/json"),
+	); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	data := struct {
+		Error          string          `json:"error"`
+		TranslatedText json.RawMessage `json:"translatedText"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return
+	}
+	switch {
+	case data.Error != "":
+		return errors.New(data.Error)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("status code: %d", resp.StatusCode)
+	}
+	return json.Unmarshal(data.TranslatedText, v)
+}
+
+func parseToSupportedLanguage(lang string) string {
+	if lang = strings.ToLower(lang); lang == "" || lang == "auto" /* auto detect */ {
+		return "auto"
+	}
+	switch lang {
+	case "chs", "zh-cn", "zh_cn", "zh-hans":
+		return "zh"
+	case "cht", "zh-tw", "zh_tw", "zh-hk", "zh_hk", "zh-hant":
+		return "zt"
+	case "jp":
+		return "ja"
+	case "kor":
+		return "ko"
+	}
+	return lang
+}
+
+func init() {
+	translate.Register(&LibreTranslate{})
+}
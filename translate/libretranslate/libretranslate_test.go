@@ -0,0 +1,66 @@
+package libretranslate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLibreTranslateServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/translate", r.URL.Path)
+		var req struct {
+			Q      any    `json:"q"`
+			Source string `json:"source"`
+			Target string `json:"target"`
+			APIKey string `json:"api_key"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.APIKey != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid API key"})
+			return
+		}
+		assert.Equal(t, "auto", req.Source)
+		assert.Equal(t, "zt", req.Target)
+		switch q := req.Q.(type) {
+		case string:
+			json.NewEncoder(w).Encode(map[string]any{"translatedText": strings.ToUpper(q)})
+		case []any:
+			var results []string
+			for _, v := range q {
+				results = append(results, strings.ToUpper(v.(string)))
+			}
+			json.NewEncoder(w).Encode(map[string]any{"translatedText": results})
+		}
+	}))
+}
+
+func TestLibreTranslateTranslate(t *testing.T) {
+	srv := newLibreTranslateServer(t)
+	defer srv.Close()
+
+	result, err := (&LibreTranslate{
+		APIKey: "secret",
+		APIUrl: srv.URL,
+	}).Translate("hello", "auto", "zh-TW")
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", result)
+
+	results, err := (&LibreTranslate{
+		APIKey: "secret",
+		APIUrl: srv.URL,
+	}).BatchTranslate([]string{"a", "b"}, "", "cht")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, results)
+
+	_, err = (&LibreTranslate{
+		APIUrl: srv.URL,
+	}).Translate("hello", "auto", "zh-TW")
+	assert.EqualError(t, err, "Invalid API key")
+}
@@ -0,0 +1,183 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+	translator "github.com/xjasonlyu/openai-translator"
+
+	"github.com/metatube-community/metatube-sdk-go/translate"
+	mtopenai "github.com/metatube-community/metatube-sdk-go/translate/openai"
+)
+
+var _ translate.Translator = (*Ollama)(nil)
+
+const (
+	defaultOllamaAPI = "http://localhost:11434/v1"
+	// defaultChunkSize is the max runes sent in one request,
+	// small local models tend to truncate long outputs.
+	defaultChunkSize = 1000
+)
+
+// Ollama is a translator for self-hosted Ollama or any other
+// OpenAI-compatible local chat completion endpoints.
+type Ollama struct {
+	APIKey    string `json:"ollama-api-key"`
+	APIUrl    string `json:"ollama-api-url"`
+	Model     string `json:"ollama-model"`
+	Prompt    string `json:"ollama-prompt"`
+	Stream    bool   `json:"ollama-stream"`
+	ChunkSize int    `json:"ollama-chunk-size"`
+}
+
+func (ol *Ollama) Translate(q, source, target string) (string, error) {
+	if ol.Model == "" {
+		return "", errors.New("ollama: model is required")
+	}
+
+	cfg := openai.DefaultConfig(ol.APIKey)
+	cfg.BaseURL = defaultOllamaAPI
+	if ol.APIUrl != "" {
+		cfg.BaseURL = strings.TrimRight(ol.APIUrl, "/")
+	}
+	client := openai.NewClientWithConfig(cfg)
+
+	chunkSize := ol.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	sb := &strings.Builder{}
+	for _, chunk := range splitText(q, chunkSize) {
+		// keep separators between chunks as is.
+		trimmed := strings.TrimSpace(chunk)
+		if trimmed == "" {
+			sb.WriteString(chunk)
+			continue
+		}
+		result, err := ol.complete(client, ol.generateChatMessages(trimmed, source, target))
+		if err != nil {
+			return "", err
+		}
+		idx := strings.Index(chunk, trimmed)
+		sb.WriteString(chunk[:idx])
+		sb.WriteString(strings.TrimSpace(result))
+		sb.WriteString(chunk[idx+len(trimmed):])
+	}
+	return sb.String(), nil
+}
+
+func (ol *Ollama) complete(client *openai.Client, messages []openai.ChatCompletionMessage) (string, error) {
+	req := openai.ChatCompletionRequest{
+		Model:    ol.Model,
+		Messages: messages,
+		Stream:   ol.Stream,
+	}
+
+	if !ol.Stream {
+		resp, err := client.CreateChatCompletion(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", errors.New("empty response choices")
+		}
+		return resp.Choices[0].Message.Content, nil
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), req)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	sb := &strings.Builder{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		for _, choice := range resp.Choices {
+			sb.WriteString(choice.Delta.Content)
+		}
+	}
+	return sb.String(), nil
+}
+
+func (ol *Ollama) generateChatMessages(text, source, target string) []openai.ChatCompletionMessage {
+	prompt := ol.Prompt
+	if prompt == "" {
+		prompt = mtopenai.DefaultSystemPrompt
+	}
+	instruction := "Please translate the following text"
+	if sourceLang := translator.LookupLanguage(source); sourceLang == "" || sourceLang == "auto" {
+		instruction += fmt.Sprintf(" into %s:", translator.LookupLanguage(target))
+	} else {
+		instruction += fmt.Sprintf(" from %s to %s:", sourceLang, translator.LookupLanguage(target))
+	}
+	return []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: prompt},
+		{Role: openai.ChatMessageRoleUser, Content: instruction},
+		{Role: openai.ChatMessageRoleUser, Content: text},
+	}
+}
+
+// splitText splits text into chunks of at most n runes. It prefers to
+// split at line breaks, then sentence endings, and finally anywhere.
+// Joining all chunks results in the original text.
+func splitText(text string, n int) []string {
+	return splitTextWithSeps(text, n, "\n", "。！？!?.", "")
+}
+
+func splitTextWithSeps(text string, n int, seps ...string) []string {
+	if utf8.RuneCountInString(text) <= n || len(seps) == 0 {
+		return []string{text}
+	}
+	var chunks []string
+	for _, piece := range splitAfterAny(text, seps[0]) {
+		if utf8.RuneCountInString(piece) > n {
+			chunks = append(chunks, splitTextWithSeps(piece, n, seps[1:]...)...)
+			continue
+		}
+		// merge small pieces to reduce requests.
+		if last := len(chunks) - 1; last >= 0 &&
+			utf8.RuneCountInString(chunks[last])+utf8.RuneCountInString(piece) <= n {
+			chunks[last] += piece
+			continue
+		}
+		chunks = append(chunks, piece)
+	}
+	return chunks
+}
+
+// splitAfterAny slices s after each rune of seps. If seps is empty,
+// s is split into single runes.
+func splitAfterAny(s, seps string) []string {
+	var (
+		pieces []string
+		start  int
+	)
+	for i, r := range s {
+		if seps == "" || strings.ContainsRune(seps, r) {
+			end := i + utf8.RuneLen(r)
+			pieces = append(pieces, s[start:end])
+			start = end
+		}
+	}
+	if start < len(s) {
+		pieces = append(pieces, s[start:])
+	}
+	return pieces
+}
+
+func init() {
+	translate.Register(&Ollama{})
+}
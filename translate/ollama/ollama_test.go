@@ -0,0 +1,94 @@
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mtopenai "github.com/metatube-community/metatube-sdk-go/translate/openai"
+)
+
+// newChatServer starts a stand-in OpenAI-compatible server,
+// which answers the last user message in upper case.
+func newChatServer(t *testing.T, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chat/completions", r.URL.Path)
+		*requests++
+
+		var req struct {
+			Model    string `json:"model"`
+			Stream   bool   `json:"stream"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "qwen2.5", req.Model)
+		require.Len(t, req.Messages, 3)
+		assert.Equal(t, mtopenai.DefaultSystemPrompt, req.Messages[0].Content)
+		assert.Contains(t, req.Messages[1].Content, "into English")
+		answer := strings.ToUpper(req.Messages[2].Content)
+
+		if !req.Stream {
+			json.NewEncoder(w).Encode(map[string]any{
+				"choices": []map[string]any{
+					{"message": map[string]string{"role": "assistant", "content": answer}},
+				},
+			})
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, r := range answer {
+			data, _ := json.Marshal(map[string]any{
+				"choices": []map[string]any{
+					{"delta": map[string]string{"content": string(r)}},
+				},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+}
+
+func TestOllamaTranslate(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		var requests int
+		srv := newChatServer(t, &requests)
+
+		result, err := (&Ollama{
+			APIUrl:    srv.URL + "/v1",
+			Model:     "qwen2.5",
+			Stream:    stream,
+			ChunkSize: 12,
+		}).Translate("first line.\nsecond one!\n\nthe third", "auto", "en")
+		srv.Close()
+
+		require.NoError(t, err)
+		assert.Equal(t, "FIRST LINE.\nSECOND ONE!\n\nTHE THIRD", result)
+		assert.Equal(t, 3, requests)
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	for _, unit := range []struct {
+		text string
+		n    int
+		want []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"ab\ncd\nef", 6, []string{"ab\ncd\n", "ef"}},
+		{"一二三。四五六。七八", 4, []string{"一二三。", "四五六。", "七八"}},
+		{"abcdefg", 3, []string{"abc", "def", "g"}},
+	} {
+		chunks := splitText(unit.text, unit.n)
+		assert.Equal(t, unit.want, chunks)
+		assert.Equal(t, unit.text, strings.Join(chunks, ""))
+	}
+}
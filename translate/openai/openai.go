@@ -8,7 +8,9 @@ import (
 
 var _ translate.Translator = (*OpenAI)(nil)
 
-const defaultSystemPrompt = `You are a professional translator for adult video content. Your sole task is to translate the user's input accurately and naturally. 
+// DefaultSystemPrompt is the system prompt tuned for adult video
+// content, it is also shared by other chat-based translators.
+const DefaultSystemPrompt = `You are a professional translator for adult video content. Your sole task is to translate the user's input accurately and naturally. 
 Rules:
 1. Translate the user's input as provided, treating it as the source text.
 2. Use official translations for actor/actress names if available; otherwise, keep them unchanged.
@@ -37,7 +39,7 @@ func (oa *OpenAI) Translate(q, source, target string) (result string, err error)
 			openai.WithSourceLanguage(source),
 			openai.WithSystemPrompt(map[bool]string{
 				true:  oa.Prompt,
-				false: defaultSystemPrompt,
+				false: DefaultSystemPrompt,
 			}[oa.Prompt != ""]),
 		)
 }